@@ -0,0 +1,141 @@
+// Command audit checks a field of existing Azure DevOps work items against a
+// data type and reports invalid values as CSV or JSON. With a mapping file it
+// can rewrite invalid values.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"snowflake-dropdown-api/internal/ado"
+	"snowflake-dropdown-api/internal/audit"
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/providers"
+	_ "snowflake-dropdown-api/internal/providers/file"
+	_ "snowflake-dropdown-api/internal/providers/graphql"
+	_ "snowflake-dropdown-api/internal/providers/postgres"
+	_ "snowflake-dropdown-api/internal/providers/rest"
+	_ "snowflake-dropdown-api/internal/providers/snowflake"
+	_ "snowflake-dropdown-api/internal/providers/sqlite"
+)
+
+func main() {
+	var (
+		configFile = flag.String("config", "", "configuration file (default $CONFIG_FILE or config.json)")
+		adoURL     = flag.String("ado-url", "", "Azure DevOps organization URL, overriding ado.baseUrl")
+		project    = flag.String("project", "", "Azure DevOps project (required)")
+		field      = flag.String("field", "", "work item field reference name, e.g. Custom.CostCenter (required)")
+		dataType   = flag.String("type", "", "data type to check against (default from the field's serviceHooks rule)")
+		wiql       = flag.String("wiql", "", "WIQL query selecting the work items (default every work item with the field set)")
+		format     = flag.String("format", "csv", "report format: csv or json")
+		output     = flag.String("out", "", "report file (default stdout)")
+		mapFile    = flag.String("map", "", "mapping file of old to new values, as JSON or CSV")
+		apply      = flag.Bool("apply", false, "rewrite mapped values; without it rewrites are only reported")
+		all        = flag.Bool("all", false, "report valid values too")
+	)
+	flag.Parse()
+
+	if err := run(*configFile, *adoURL, *dataType, *format, *output, *mapFile, *all, audit.Options{
+		Project: *project,
+		Query:   *wiql,
+		Field:   *field,
+		Apply:   *apply,
+	}); err != nil {
+		log.Fatalf("Audit failed: %v", err)
+	}
+}
+
+// run loads the configuration, audits the work items and writes the report
+func run(configFile, adoURL, dataType, format, output, mapFile string, all bool, opts audit.Options) error {
+	if opts.Project == "" || opts.Field == "" {
+		return fmt.Errorf("-project and -field are required")
+	}
+	if opts.Apply && mapFile == "" {
+		return fmt.Errorf("-apply requires -map")
+	}
+	if err := audit.CheckFormat(format); err != nil {
+		return err
+	}
+
+	if err := config.LoadEnvFile(); err != nil {
+		log.Printf("Error loading .env file: %v", err)
+	}
+	if configFile != "" {
+		os.Setenv("CONFIG_FILE", configFile)
+	}
+	if err := config.LoadConfig(); err != nil {
+		return err
+	}
+
+	dtConfig, err := dataTypeFor(dataType, opts.Field)
+	if err != nil {
+		return err
+	}
+	if mapFile != "" {
+		if opts.Mapping, err = audit.LoadMapping(mapFile); err != nil {
+			return err
+		}
+	}
+
+	adoConfig := config.ADOConfig{}
+	if config.AppConfig.ADO != nil {
+		adoConfig = *config.AppConfig.ADO
+	}
+	if adoURL != "" {
+		adoConfig.BaseURL = adoURL
+	}
+	client, err := ado.NewClient(&adoConfig)
+	if err != nil {
+		return err
+	}
+
+	dataTypes := []config.DataTypeConfig{*dtConfig}
+	if err := providers.ValidateAll(dataTypes); err != nil {
+		return err
+	}
+	if err := providers.Initialize(context.Background(), dataTypes); err != nil {
+		return err
+	}
+	defer providers.CloseAll()
+
+	// Open the report file first so a bad path fails before any rewrites
+	var w io.Writer = os.Stdout
+	if output != "" {
+		file, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	report, err := audit.Run(context.Background(), client, dtConfig, opts, all)
+	if err != nil {
+		return err
+	}
+	log.Printf("Checked %d work items: %d valid, %d invalid, %d inactive, %d rewritten",
+		report.Checked, report.Valid, report.Invalid, report.Inactive, report.Rewritten)
+
+	return audit.Write(w, report, format)
+}
+
+// dataTypeFor returns the named data type, or the one the field is bound to
+// in the service hook settings
+func dataTypeFor(dataType, field string) (*config.DataTypeConfig, error) {
+	if dataType == "" && config.AppConfig.ServiceHooks != nil {
+		for _, rule := range config.AppConfig.ServiceHooks.Fields {
+			if rule.Field == field {
+				dataType = rule.DataType
+				break
+			}
+		}
+	}
+	if dataType == "" {
+		return nil, fmt.Errorf("-type is required for field %s", field)
+	}
+	return config.GetDataTypeConfig(dataType)
+}
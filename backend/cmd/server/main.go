@@ -1,14 +1,25 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"log"
 	"net/http"
 	"os"
 
 	"snowflake-dropdown-api/internal/api"
+	"snowflake-dropdown-api/internal/cache"
 	"snowflake-dropdown-api/internal/config"
-	"snowflake-dropdown-api/internal/database"
+	"snowflake-dropdown-api/internal/providers"
+	_ "snowflake-dropdown-api/internal/providers/file"
+	_ "snowflake-dropdown-api/internal/providers/graphql"
+	_ "snowflake-dropdown-api/internal/providers/postgres"
+	_ "snowflake-dropdown-api/internal/providers/rest"
+	_ "snowflake-dropdown-api/internal/providers/snowflake"
+	_ "snowflake-dropdown-api/internal/providers/sqlite"
+	"snowflake-dropdown-api/internal/scheduler"
+	"snowflake-dropdown-api/internal/snapshot"
+	"snowflake-dropdown-api/internal/workitems"
 )
 
 func main() {
@@ -17,22 +28,56 @@ func main() {
 		log.Printf("Error loading .env file: %v", err)
 	}
 
-	// Check required environment variables (skip if in TEST_MODE)
+	// Load dynamic configuration
+	if err := config.LoadConfig(); err != nil {
+		log.Printf("Warning: Failed to load config: %v", err)
+		log.Println("Using default configuration")
+	}
+
+	// Select the cache backend and apply cache settings
+	if config.AppConfig != nil {
+		backend, err := cache.New(config.AppConfig.CacheSettings)
+		if err != nil {
+			log.Fatalf("Cache setup failed: %v", err)
+		}
+		cache.Instance = backend
+	}
+	defer cache.Instance.Close()
+
+	// Check provider settings and query placeholders for the enabled data
+	// types (skip if in TEST_MODE)
 	if err := validateEnvironment(); err != nil {
 		log.Fatalf("Environment validation failed: %v", err)
 	}
 
-	// Initialize database connection
-	if err := database.InitializeDatabase(); err != nil {
-		log.Printf("Warning: Database initialization failed: %v", err)
-		log.Println("Server will start but database queries will fail")
+	// Check the service hook field rules against the enabled data types
+	// (skip if in TEST_MODE)
+	if os.Getenv("TEST_MODE") != "true" && config.AppConfig != nil && config.AppConfig.ServiceHooks != nil {
+		if err := workitems.ValidateConfig(config.AppConfig.ServiceHooks, config.AppConfig.ADO); err != nil {
+			log.Fatalf("Service hook configuration invalid: %v", err)
+		}
+	}
+
+	// Initialize data providers
+	if err := providers.Initialize(context.Background(), config.GetEnabledDataTypes()); err != nil {
+		log.Printf("Warning: Provider initialization failed: %v", err)
+		log.Println("Server will start but queries against failed providers will fail")
 		log.Println("Consider using TEST_MODE=true for testing without database")
 	}
+	defer providers.CloseAll()
 
-	// Load dynamic configuration
-	if err := config.LoadConfig(); err != nil {
-		log.Printf("Warning: Failed to load config: %v", err)
-		log.Println("Using default configuration")
+	// Check the columns returned by each query against the database
+	if err := validateQueries(); err != nil {
+		log.Fatalf("Query validation failed: %v", err)
+	}
+
+	// Load snapshot-mode data types into memory and keep them refreshed, and
+	// start the scheduled cache warm-up and snapshot refresh jobs
+	if os.Getenv("TEST_MODE") != "true" {
+		snapshot.Start(context.Background(), config.GetEnabledDataTypes())
+		if err := scheduler.Start(context.Background(), config.GetEnabledDataTypes()); err != nil {
+			log.Fatalf("Scheduler setup failed: %v", err)
+		}
 	}
 
 	// Setup router and middleware
@@ -52,33 +97,39 @@ func main() {
 	}
 }
 
-// validateEnvironment checks required environment variables
+// validateEnvironment checks each enabled data type against its provider
 func validateEnvironment() error {
 	if os.Getenv("TEST_MODE") == "true" {
 		log.Println("Running in TEST_MODE - using mock data")
 		return nil
 	}
 
-	required := []string{
-		"SNOWFLAKE_ACCOUNT",
-		"SNOWFLAKE_USER",
-		"SNOWFLAKE_DATABASE",
-		"SNOWFLAKE_SCHEMA",
-		"SNOWFLAKE_WAREHOUSE",
-		"SNOWFLAKE_ROLE",
-	}
+	return handleInvalid(providers.ValidateAll(config.GetEnabledDataTypes()))
+}
 
-	// Only require password if not using SSO
-	if os.Getenv("SNOWFLAKE_AUTH_TYPE") != "externalbrowser" {
-		required = append(required, "SNOWFLAKE_PASSWORD")
+// validateQueries describes each enabled data type's queries when enabled
+// in the validation settings
+func validateQueries() error {
+	if os.Getenv("TEST_MODE") == "true" || config.AppConfig == nil || !config.AppConfig.Validation.DescribeQueries {
+		return nil
 	}
 
-	for _, env := range required {
-		if os.Getenv(env) == "" {
-			return fmt.Errorf("missing required environment variable: %s", env)
-		}
+	log.Println("Checking data type queries against their databases")
+	return handleInvalid(providers.DescribeAll(context.Background(), config.GetEnabledDataTypes()))
+}
+
+// handleInvalid disables the data types that failed validation when the
+// validation settings say so, and otherwise returns the error
+func handleInvalid(err error) error {
+	var invalid *providers.ValidationError
+	if !errors.As(err, &invalid) || config.AppConfig.Validation.OnError != "disable" {
+		return err
 	}
 
+	for _, id := range invalid.DataTypes() {
+		log.Printf("Disabling data type '%s': %v", id, invalid.Failures[id])
+		config.DisableDataType(id)
+	}
 	return nil
 }
 
@@ -89,6 +140,16 @@ func logEndpoints() {
 	log.Printf("  GET /api/config - Get data types configuration")
 	log.Printf("  GET /api/search/{type} - Search with dynamic data type")
 	log.Printf("  GET /api/types - List available data types")
+	log.Printf("  GET /api/items/{type}/{value} - Look up an item by value")
+	log.Printf("  POST /api/items/{type}/resolve - Resolve a list of values to items")
+	log.Printf("  POST /api/validate/{type} - Check values exist and are active")
+	log.Printf("  POST /api/hooks/workitems - Azure DevOps work item service hook")
+	log.Printf("  GET /api/stats/search - Search and request coalescing counters")
+	log.Printf("  GET /api/admin/cache - Cache entries and hit counters (admin)")
+	log.Printf("  DELETE /api/admin/cache[/{type}] - Invalidate cached searches (admin)")
+	log.Printf("  POST /api/admin/cache/warm - Pre-run searches into the cache (admin)")
+	log.Printf("  GET /api/admin/violations - Invalid work item values from service hooks (admin)")
+	log.Printf("  GET /api/admin/jobs - Scheduled warm-up and snapshot refresh status (admin)")
 	log.Printf("  POST /api/dynamic-search - Custom query endpoint")
 	log.Printf("")
 	log.Printf("Dynamic configuration loaded from: %s", getConfigFile())
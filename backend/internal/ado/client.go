@@ -0,0 +1,99 @@
+// Package ado is a minimal client for the Azure DevOps work item REST API
+package ado
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/providers"
+)
+
+// API versions of the endpoints used
+const (
+	apiVersion        = "7.1"
+	commentAPIVersion = "7.1-preview.4"
+)
+
+// Client calls the REST API of one Azure DevOps organization
+type Client struct {
+	baseURL string
+	auth    *config.AuthConfig
+	timeout time.Duration
+	retries int
+	http    *http.Client
+}
+
+// NewClient returns a client for the configured organization
+func NewClient(cfg *config.ADOConfig) (*Client, error) {
+	if cfg == nil || cfg.BaseURL == "" {
+		return nil, fmt.Errorf("ado.baseUrl is required")
+	}
+	if err := providers.ValidateAuth(cfg.Auth); err != nil {
+		return nil, fmt.Errorf("ado: %v", err)
+	}
+
+	return &Client{
+		baseURL: strings.TrimRight(config.ResolveValue(cfg.BaseURL), "/"),
+		auth:    cfg.Auth,
+		timeout: providers.UpstreamTimeout(cfg.TimeoutSeconds),
+		retries: cfg.Retries,
+		http:    &http.Client{},
+	}, nil
+}
+
+// AddComment adds a comment to a work item
+func (c *Client) AddComment(ctx context.Context, project string, id int, text string) error {
+	path := fmt.Sprintf("/%s/_apis/wit/workItems/%d/comments", url.PathEscape(project), id)
+	_, err := c.do(ctx, http.MethodPost, path, commentAPIVersion, "application/json", map[string]string{"text": text})
+	return err
+}
+
+// UpdateFields sets work item fields to the given values. A non-zero rev
+// makes the update fail if the work item changed since that revision.
+func (c *Client) UpdateFields(ctx context.Context, id, rev int, fields map[string]string) error {
+	type operation struct {
+		Op    string      `json:"op"`
+		Path  string      `json:"path"`
+		Value interface{} `json:"value"`
+	}
+	patch := make([]operation, 0, len(fields)+1)
+	if rev != 0 {
+		patch = append(patch, operation{Op: "test", Path: "/rev", Value: rev})
+	}
+	for field, value := range fields {
+		patch = append(patch, operation{Op: "add", Path: "/fields/" + field, Value: value})
+	}
+
+	path := fmt.Sprintf("/_apis/wit/workitems/%d", id)
+	_, err := c.do(ctx, http.MethodPatch, path, apiVersion, "application/json-patch+json", patch)
+	return err
+}
+
+// do sends a request with a JSON body and returns the response body
+func (c *Client) do(ctx context.Context, method, path, version, contentType string, body interface{}) ([]byte, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := c.baseURL + path + "?api-version=" + version
+
+	return providers.DoWithRetry(ctx, c.http, c.retries, c.timeout, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("Accept", "application/json")
+		if err := providers.ApplyAuth(req, c.auth); err != nil {
+			return nil, err
+		}
+		return req, nil
+	})
+}
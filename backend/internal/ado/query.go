@@ -0,0 +1,76 @@
+package ado
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// batchSize is the most work items the batch endpoint returns per request
+const batchSize = 200
+
+// WorkItem is a work item with the requested fields
+type WorkItem struct {
+	ID     int                    `json:"id"`
+	Rev    int                    `json:"rev"`
+	Fields map[string]interface{} `json:"fields"`
+}
+
+// QueryIDs runs a WIQL query in a project and returns the ids of the work
+// items it selects. Azure DevOps rejects queries selecting over 20,000 items.
+func (c *Client) QueryIDs(ctx context.Context, project, wiql string) ([]int, error) {
+	path := fmt.Sprintf("/%s/_apis/wit/wiql", url.PathEscape(project))
+	body, err := c.do(ctx, http.MethodPost, path, apiVersion, "application/json", map[string]string{"query": wiql})
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		WorkItems []struct {
+			ID int `json:"id"`
+		} `json:"workItems"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("parsing WIQL response: %v", err)
+	}
+	ids := make([]int, len(result.WorkItems))
+	for i, item := range result.WorkItems {
+		ids[i] = item.ID
+	}
+	return ids, nil
+}
+
+// GetWorkItems returns the given fields of the work items with the given ids,
+// fetched in batches. Work items that no longer exist are left out.
+func (c *Client) GetWorkItems(ctx context.Context, project string, ids []int, fields []string) ([]WorkItem, error) {
+	path := fmt.Sprintf("/%s/_apis/wit/workitemsbatch", url.PathEscape(project))
+
+	items := make([]WorkItem, 0, len(ids))
+	for start := 0; start < len(ids); start += batchSize {
+		end := start + batchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		request := map[string]interface{}{"ids": ids[start:end], "fields": fields, "errorPolicy": "omit"}
+		body, err := c.do(ctx, http.MethodPost, path, apiVersion, "application/json", request)
+		if err != nil {
+			return nil, err
+		}
+
+		var result struct {
+			Value []*WorkItem `json:"value"`
+		}
+		if err := json.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("parsing work items response: %v", err)
+		}
+		for _, item := range result.Value {
+			if item != nil {
+				items = append(items, *item)
+			}
+		}
+	}
+	return items, nil
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"snowflake-dropdown-api/internal/cache"
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/search"
+
+	"github.com/gorilla/mux"
+)
+
+// warmRequest selects the data types and search terms to warm. Omitted
+// fields default to every enabled data type and its configured warm terms.
+type warmRequest struct {
+	Types    []string `json:"types"`
+	Prefixes []string `json:"prefixes"`
+}
+
+// HandleCacheStats returns the cache entries and hit counters per data type
+func HandleCacheStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(search.CacheStats(config.GetEnabledDataTypes()))
+}
+
+// HandleInvalidateCache removes all cached searches for one data type
+func HandleInvalidateCache(w http.ResponseWriter, r *http.Request) {
+	dtConfig, err := config.GetDataTypeConfig(mux.Vars(r)["type"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	search.InvalidateCache(dtConfig.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleClearCache removes all cached searches, or only those whose keys
+// match the glob given as the pattern query parameter
+func HandleClearCache(w http.ResponseWriter, r *http.Request) {
+	pattern := r.URL.Query().Get("pattern")
+	if pattern == "" {
+		cache.Instance.Clear()
+		search.ClearCandidates()
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	deleted, err := cache.DeleteMatching(cache.Instance, pattern)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"deleted": deleted})
+}
+
+// HandleWarmCache runs the configured queries for the requested data types
+// and search prefixes and caches their results
+func HandleWarmCache(w http.ResponseWriter, r *http.Request) {
+	var request warmRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var dataTypes []config.DataTypeConfig
+	if len(request.Types) == 0 {
+		dataTypes = config.GetEnabledDataTypes()
+	}
+	for _, id := range request.Types {
+		dtConfig, err := config.GetDataTypeConfig(id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		dataTypes = append(dataTypes, *dtConfig)
+	}
+
+	results := []search.WarmResult{}
+	for i := range dataTypes {
+		terms := request.Prefixes
+		if len(terms) == 0 {
+			terms = search.WarmTerms(&dataTypes[i])
+		}
+		results = append(results, search.Warm(r.Context(), &dataTypes[i], terms)...)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
@@ -22,6 +22,8 @@ func HandleGetConfig(w http.ResponseWriter, r *http.Request) {
 			Name:        dt.Name,
 			Description: dt.Description,
 			Icon:        dt.Icon,
+			Params:      paramInfo(dt.Params),
+			Attributes:  attributeInfo(dt.Attributes),
 		})
 	}
 
@@ -59,4 +61,45 @@ func HandleGetDataTypes(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
-}
\ No newline at end of file
+}
+
+// paramInfo describes context parameters for the frontend, defaulting their
+// cascade behavior
+func paramInfo(params []config.ParamConfig) []models.ParamInfo {
+	var info []models.ParamInfo
+	for _, param := range params {
+		cascade := param.Cascade
+		if cascade == "" {
+			cascade = config.CascadeClear
+		}
+		info = append(info, models.ParamInfo{
+			Name:      param.Name,
+			DependsOn: param.DependsOn,
+			Required:  param.Required,
+			Cascade:   cascade,
+		})
+	}
+	return info
+}
+
+// attributeInfo describes item attributes for the frontend, defaulting their
+// label and display style
+func attributeInfo(attributes []config.AttributeConfig) []models.AttributeInfo {
+	var info []models.AttributeInfo
+	for _, attribute := range attributes {
+		label := attribute.Label
+		if label == "" {
+			label = attribute.Name
+		}
+		display := attribute.Display
+		if display == "" {
+			display = config.DisplayText
+		}
+		info = append(info, models.AttributeInfo{
+			Name:    attribute.Name,
+			Label:   label,
+			Display: display,
+		})
+	}
+	return info
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/providers"
+	"snowflake-dropdown-api/internal/workitems"
+)
+
+// maxHookBody bounds the size of service hook requests
+const maxHookBody = 1 << 20
+
+// HandleWorkItemHook checks the fields of work items sent by Azure DevOps
+// workitem.created and workitem.updated service hooks
+func HandleWorkItemHook(w http.ResponseWriter, r *http.Request) {
+	if config.AppConfig == nil || config.AppConfig.ServiceHooks == nil {
+		http.Error(w, "Service hooks are not configured", http.StatusNotFound)
+		return
+	}
+	hooks := config.AppConfig.ServiceHooks
+
+	secret := config.ResolveValue(hooks.Secret)
+	if secret == "" {
+		log.Printf("Service hook rejected: serviceHooks.secret is not set")
+	}
+	if !validHookSecret(r, secret) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxHookBody))
+	if err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	event, err := workitems.ParseEvent(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := workitems.Process(r.Context(), hooks, config.AppConfig.ADO, event)
+	if err != nil {
+		log.Printf("Service hook error for work item %d: %v", event.WorkItemID, err)
+		http.Error(w, "Validation failed", providers.HTTPStatusFor(err))
+		return
+	}
+	if len(report.Violations) > 0 {
+		log.Printf("Service hook: work item %d has %d invalid field value(s)", event.WorkItemID, len(report.Violations))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// HandleViolations returns the invalid work item field values found by
+// service hooks, newest first
+func HandleViolations(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(workitems.Violations())
+}
+
+// validHookSecret reports whether a request carries the service hook secret,
+// as the basic auth password or in the X-Hook-Secret header. Every request is
+// rejected when no secret is configured.
+func validHookSecret(r *http.Request, secret string) bool {
+	if secret == "" {
+		return false
+	}
+	provided := r.Header.Get("X-Hook-Secret")
+	if _, password, ok := r.BasicAuth(); ok {
+		provided = password
+	}
+	return subtle.ConstantTimeCompare([]byte(provided), []byte(secret)) == 1
+}
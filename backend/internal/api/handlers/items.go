@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/models"
+	"snowflake-dropdown-api/internal/providers"
+	"snowflake-dropdown-api/internal/search"
+
+	"github.com/gorilla/mux"
+)
+
+// maxRequestValues bounds the values resolved or validated in one request
+const maxRequestValues = 1000
+
+// HandleGetItem returns the item with exactly the given value
+func HandleGetItem(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	dtConfig, err := config.GetDataTypeConfig(vars["type"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	item, err := search.Lookup(r.Context(), dtConfig, vars["value"])
+	if err != nil {
+		log.Printf("Lookup error: %v", err)
+		http.Error(w, "Lookup failed", providers.HTTPStatusFor(err))
+		return
+	}
+	if item == nil {
+		http.Error(w, fmt.Sprintf("No %s item with value '%s'", dtConfig.ID, vars["value"]), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(item)
+}
+
+// HandleResolveItems returns the items for a list of values and the values
+// that matched no item
+func HandleResolveItems(w http.ResponseWriter, r *http.Request) {
+	dtConfig, err := config.GetDataTypeConfig(mux.Vars(r)["type"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var request models.ResolveRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	if len(request.Values) > maxRequestValues {
+		http.Error(w, fmt.Sprintf("At most %d values can be resolved at once", maxRequestValues), http.StatusBadRequest)
+		return
+	}
+
+	response, err := search.Resolve(r.Context(), dtConfig, request.Values)
+	if err != nil {
+		log.Printf("Resolve error: %v", err)
+		http.Error(w, "Lookup failed", providers.HTTPStatusFor(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,15 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"snowflake-dropdown-api/internal/scheduler"
+)
+
+// HandleJobStatus returns the last run and next run of each scheduled
+// warm-up or snapshot refresh job
+func HandleJobStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(scheduler.Statuses())
+}
@@ -2,21 +2,26 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"snowflake-dropdown-api/internal/config"
-	"snowflake-dropdown-api/internal/database"
 	"snowflake-dropdown-api/internal/models"
+	"snowflake-dropdown-api/internal/providers"
+	"snowflake-dropdown-api/internal/search"
 
 	"github.com/gorilla/mux"
 )
 
-// HandleSearch performs a search using the dynamic configuration
+// HandleSearch performs a search using the dynamic configuration. The
+// optional limit and cursor parameters page through the results, and the
+// data type's context parameters narrow them.
 func HandleSearch(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	dataType := vars["type"]
@@ -42,41 +47,71 @@ func HandleSearch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Build and execute query
-	query, params := database.BuildSearchQuery(dtConfig, searchTerm)
-
-	rows, err := database.DB.Query(query, params...)
+	dtConfig, err = withContext(r, dtConfig)
 	if err != nil {
-		log.Printf("Query error: %v", err)
-		http.Error(w, "Search failed", http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	defer rows.Close()
 
-	var items []models.DropdownItem
-	for rows.Next() {
-		var item models.DropdownItem
-		if err := rows.Scan(&item.Value, &item.Label); err != nil {
-			log.Printf("Error scanning row: %v", err)
-			continue
-		}
-		items = append(items, item)
+	page, err := parsePage(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	response := models.DropdownResponse{
-		Data: items,
-		Metadata: models.Metadata{
-			ExportedAt: time.Now().UTC(),
-			RowCount:   len(items),
-			Source:     dataType,
-			Cached:     false,
-		},
+	response, err := search.ExecutePage(r.Context(), dtConfig, searchTerm, page)
+	if errors.Is(err, providers.ErrInvalidCursor) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Query error: %v", err)
+		http.Error(w, "Search failed", providers.HTTPStatusFor(err))
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
+// withContext binds the data type's context parameters from the query
+// string, rejecting searches without a required one
+func withContext(r *http.Request, dtConfig *config.DataTypeConfig) (*config.DataTypeConfig, error) {
+	if len(dtConfig.Params) == 0 {
+		return dtConfig, nil
+	}
+
+	values := make(map[string]string, len(dtConfig.Params))
+	for _, param := range dtConfig.Params {
+		value := strings.TrimSpace(r.URL.Query().Get(param.Name))
+		if value == "" && param.Required {
+			return nil, fmt.Errorf("parameter '%s' is required", param.Name)
+		}
+		values[param.Name] = value
+	}
+	return dtConfig.WithContext(values), nil
+}
+
+// parsePage reads the limit and cursor query parameters
+func parsePage(r *http.Request) (providers.Page, error) {
+	var page providers.Page
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return page, fmt.Errorf("limit must be a positive number")
+		}
+		page.Limit = n
+	}
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		after, err := providers.DecodeCursor(cursor)
+		if err != nil {
+			return page, err
+		}
+		page.After = after
+	}
+	return page, nil
+}
+
 // HandleDynamicSearch handles custom queries with security validation
 func HandleDynamicSearch(w http.ResponseWriter, r *http.Request) {
 	var request models.DynamicSearchRequest
@@ -98,22 +133,17 @@ func HandleDynamicSearch(w http.ResponseWriter, r *http.Request) {
 		params[i] = p
 	}
 
-	rows, err := database.DB.Query(request.Query, params...)
+	executor, err := dynamicQueryExecutor(request.DataType)
 	if err != nil {
-		log.Printf("Dynamic query error: %v", err)
-		http.Error(w, "Query execution failed", http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	defer rows.Close()
 
-	var items []models.DropdownItem
-	for rows.Next() {
-		var item models.DropdownItem
-		if err := rows.Scan(&item.Value, &item.Label); err != nil {
-			log.Printf("Error scanning row: %v", err)
-			continue
-		}
-		items = append(items, item)
+	items, err := executor.Query(r.Context(), request.Query, params)
+	if err != nil {
+		log.Printf("Dynamic query error: %v", err)
+		http.Error(w, "Query execution failed", http.StatusInternalServerError)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -128,6 +158,29 @@ func HandleDynamicSearch(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// dynamicQueryExecutor resolves the provider used for a dynamic query
+func dynamicQueryExecutor(dataType string) (providers.QueryExecutor, error) {
+	name := providers.DefaultProvider
+	if dataType != "" {
+		dtConfig, err := config.GetDataTypeConfig(dataType)
+		if err != nil {
+			return nil, err
+		}
+		name = providers.NameFor(dtConfig)
+	}
+
+	provider, err := providers.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	executor, ok := provider.(providers.QueryExecutor)
+	if !ok {
+		return nil, fmt.Errorf("provider '%s' does not support dynamic queries", name)
+	}
+	return executor, nil
+}
+
 // handleMockSearch returns mock data for testing
 func handleMockSearch(w http.ResponseWriter, dataType, searchTerm string) {
 	var items []models.DropdownItem
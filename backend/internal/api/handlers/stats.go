@@ -0,0 +1,15 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"snowflake-dropdown-api/internal/search"
+)
+
+// HandleSearchStats returns per data type counters for uncached searches,
+// including how many were coalesced into another request's query
+func HandleSearchStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(search.Stats())
+}
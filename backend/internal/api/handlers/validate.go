@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/models"
+	"snowflake-dropdown-api/internal/providers"
+	"snowflake-dropdown-api/internal/validation"
+
+	"github.com/gorilla/mux"
+)
+
+// validateResponse reports whether every value is valid and the result of each
+type validateResponse struct {
+	DataType string              `json:"dataType"`
+	Valid    bool                `json:"valid"`
+	Results  []validation.Result `json:"results"`
+}
+
+// HandleValidateValues checks that values exist and are active
+func HandleValidateValues(w http.ResponseWriter, r *http.Request) {
+	dtConfig, err := config.GetDataTypeConfig(mux.Vars(r)["type"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var request models.ValidateRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || len(request.Values) == 0 {
+		http.Error(w, "Invalid request: values are required", http.StatusBadRequest)
+		return
+	}
+	if len(request.Values) > maxRequestValues {
+		http.Error(w, fmt.Sprintf("At most %d values can be validated at once", maxRequestValues), http.StatusBadRequest)
+		return
+	}
+
+	results, err := validation.Check(r.Context(), dtConfig, request.Values)
+	if err != nil {
+		log.Printf("Validation error: %v", err)
+		http.Error(w, "Validation failed", providers.HTTPStatusFor(err))
+		return
+	}
+
+	response := validateResponse{DataType: dtConfig.ID, Valid: true, Results: results}
+	for _, result := range results {
+		if !result.Valid() {
+			response.Valid = false
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -146,4 +146,25 @@ func isValidJWT(tokenString, secret string) bool {
 	})
 
 	return err == nil && token.Valid
-}
\ No newline at end of file
+}
+
+// AdminKeyMiddleware guards admin endpoints with the ADMIN_API_KEY secret,
+// sent in the X-Admin-Key header. Admin endpoints are disabled when it is unset.
+func AdminKeyMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			adminKey := os.Getenv("ADMIN_API_KEY")
+			if adminKey == "" {
+				http.Error(w, "Admin API disabled", http.StatusForbidden)
+				return
+			}
+
+			if !isValidAPIKey(r.Header.Get("X-Admin-Key"), []string{adminKey}) {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
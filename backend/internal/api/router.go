@@ -26,6 +26,20 @@ func SetupRouter() http.Handler {
 	api.HandleFunc("/config", handlers.HandleGetConfig).Methods("GET", "OPTIONS")
 	api.HandleFunc("/search/{type}", handlers.HandleSearch).Methods("GET", "OPTIONS")
 	api.HandleFunc("/types", handlers.HandleGetDataTypes).Methods("GET", "OPTIONS")
+	api.HandleFunc("/items/{type}/resolve", handlers.HandleResolveItems).Methods("POST", "OPTIONS")
+	api.HandleFunc("/items/{type}/{value}", handlers.HandleGetItem).Methods("GET", "OPTIONS")
+	api.HandleFunc("/validate/{type}", handlers.HandleValidateValues).Methods("POST", "OPTIONS")
+	api.HandleFunc("/stats/search", handlers.HandleSearchStats).Methods("GET", "OPTIONS")
+
+	// Admin endpoints, guarded by ADMIN_API_KEY
+	admin := api.PathPrefix("/admin").Subrouter()
+	admin.Use(middleware.AdminKeyMiddleware())
+	admin.HandleFunc("/cache", handlers.HandleCacheStats).Methods("GET")
+	admin.HandleFunc("/cache", handlers.HandleClearCache).Methods("DELETE")
+	admin.HandleFunc("/cache/warm", handlers.HandleWarmCache).Methods("POST")
+	admin.HandleFunc("/cache/{type}", handlers.HandleInvalidateCache).Methods("DELETE")
+	admin.HandleFunc("/violations", handlers.HandleViolations).Methods("GET")
+	admin.HandleFunc("/jobs", handlers.HandleJobStatus).Methods("GET")
 
 	/*     // Legacy endpoints for backward compatibility
 	       api.HandleFunc("/dropdown/{type}", handlers.HandleDropdownData).Methods("GET", "OPTIONS")
@@ -51,6 +65,13 @@ func SetupRouter() http.Handler {
 		handler = middleware.AuthMiddleware(secConfig)(handler)
 	}
 
+	// Service hooks authenticate with the hook secret, so they are served
+	// outside the API key and authentication middleware
+	root := mux.NewRouter()
+	root.HandleFunc("/api/hooks/workitems", handlers.HandleWorkItemHook).Methods("POST")
+	root.PathPrefix("/").Handler(handler)
+	handler = root
+
 	// Apply rate limiting if configured
 	if rateLimit := getRateLimit(); rateLimit > 0 {
 		log.Printf("Rate limiting enabled: %d requests per minute", rateLimit)
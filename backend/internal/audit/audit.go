@@ -0,0 +1,180 @@
+// Package audit checks the values stored in a field of existing Azure DevOps
+// work items against a data type, and optionally rewrites invalid values
+// through a mapping
+package audit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"snowflake-dropdown-api/internal/ado"
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/validation"
+	"snowflake-dropdown-api/internal/workitems"
+)
+
+// Rewrite actions
+const (
+	ActionPlanned   = "planned"   // would be rewritten without Options.Apply
+	ActionRewritten = "rewritten" // rewritten in Azure DevOps
+	ActionSkipped   = "skipped"   // the mapped value is not valid either
+	ActionFailed    = "failed"    // the update was rejected
+)
+
+// Options select the work items and field to audit
+type Options struct {
+	Project string
+	Query   string // WIQL; defaults to every work item in the project with the field set
+	Field   string // reference name, e.g. Custom.CostCenter
+
+	// Mapping rewrites invalid values, matched case-insensitively, to the
+	// mapped value. Rewrites are only planned unless Apply is set.
+	Mapping map[string]string
+	Apply   bool
+}
+
+// Row is the audit result of one work item
+type Row struct {
+	WorkItemID   int    `json:"workItemId"`
+	WorkItemType string `json:"workItemType"`
+	Title        string `json:"title"`
+	Value        string `json:"value"`
+	Status       string `json:"status"`
+	Reason       string `json:"reason,omitempty"`
+	NewValue     string `json:"newValue,omitempty"`
+	Action       string `json:"action,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// Report is the outcome of an audit
+type Report struct {
+	DataType  string `json:"dataType"`
+	Field     string `json:"field"`
+	Checked   int    `json:"checked"`
+	Valid     int    `json:"valid"`
+	Invalid   int    `json:"invalid"`
+	Inactive  int    `json:"inactive"`
+	Rewritten int    `json:"rewritten"`
+	Rows      []Row  `json:"rows"`
+}
+
+// DefaultQuery returns the WIQL selecting every work item in the project
+// whose field is set
+func DefaultQuery(field string) string {
+	return fmt.Sprintf("SELECT [System.Id] FROM WorkItems WHERE [System.TeamProject] = @project AND [%s] <> '' ORDER BY [System.Id]", field)
+}
+
+// Run queries the work items, checks each distinct field value once and
+// rewrites mapped values. Rows of valid values are left out of the report
+// unless all is set.
+func Run(ctx context.Context, client *ado.Client, dtConfig *config.DataTypeConfig, opts Options, all bool) (*Report, error) {
+	query := opts.Query
+	if query == "" {
+		query = DefaultQuery(opts.Field)
+	}
+
+	ids, err := client.QueryIDs(ctx, opts.Project, query)
+	if err != nil {
+		return nil, fmt.Errorf("running WIQL query: %w", err)
+	}
+	log.Printf("Auditing %s on %d work items", opts.Field, len(ids))
+
+	items, err := client.GetWorkItems(ctx, opts.Project, ids, []string{"System.Id", "System.WorkItemType", "System.Title", opts.Field})
+	if err != nil {
+		return nil, fmt.Errorf("fetching work items: %w", err)
+	}
+
+	// Check the stored values together with the values they map to
+	var values []string
+	for _, item := range items {
+		if value := workitems.FieldValue(item.Fields[opts.Field]); value != "" {
+			values = append(values, value)
+		}
+	}
+	mapping := normalize(opts.Mapping)
+	for _, target := range mapping {
+		values = append(values, target)
+	}
+	checked, err := validation.Check(ctx, dtConfig, values)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string]validation.Result, len(checked))
+	for _, result := range checked {
+		results[result.Value] = result
+	}
+
+	report := &Report{DataType: dtConfig.ID, Field: opts.Field, Rows: []Row{}}
+	for _, item := range items {
+		value := workitems.FieldValue(item.Fields[opts.Field])
+		if value == "" {
+			continue
+		}
+		result := results[value]
+		row := Row{
+			WorkItemID:   item.ID,
+			WorkItemType: workitems.FieldValue(item.Fields["System.WorkItemType"]),
+			Title:        workitems.FieldValue(item.Fields["System.Title"]),
+			Value:        value,
+			Status:       result.Status,
+			Reason:       result.Reason,
+		}
+
+		report.Checked++
+		switch result.Status {
+		case validation.StatusValid:
+			report.Valid++
+		case validation.StatusInactive:
+			report.Inactive++
+		default:
+			report.Invalid++
+		}
+		if !result.Valid() {
+			rewrite(ctx, client, opts, item, mapping, results, &row)
+			if row.Action == ActionRewritten {
+				report.Rewritten++
+			}
+		}
+		if all || !result.Valid() {
+			report.Rows = append(report.Rows, row)
+		}
+	}
+	return report, nil
+}
+
+// rewrite sets an invalid value to its mapped value, recording the action
+// on the row. Updates fail if the work item changed since it was read.
+func rewrite(ctx context.Context, client *ado.Client, opts Options, item ado.WorkItem, mapping map[string]string, results map[string]validation.Result, row *Row) {
+	target, ok := mapping[strings.ToLower(row.Value)]
+	if !ok {
+		return
+	}
+	row.NewValue = target
+
+	switch {
+	case !results[target].Valid():
+		row.Action = ActionSkipped
+		row.Error = results[target].Reason
+	case !opts.Apply:
+		row.Action = ActionPlanned
+	default:
+		if err := client.UpdateFields(ctx, item.ID, item.Rev, map[string]string{opts.Field: target}); err != nil {
+			log.Printf("Rewriting work item %d: %v", item.ID, err)
+			row.Action = ActionFailed
+			row.Error = err.Error()
+			return
+		}
+		row.Action = ActionRewritten
+	}
+}
+
+// normalize returns the mapping keyed by trimmed, lower-cased value
+func normalize(mapping map[string]string) map[string]string {
+	result := make(map[string]string, len(mapping))
+	for from, to := range mapping {
+		result[strings.ToLower(strings.TrimSpace(from))] = strings.TrimSpace(to)
+	}
+	return result
+}
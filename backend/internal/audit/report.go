@@ -0,0 +1,88 @@
+package audit
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// csvHeader lists the columns of CSV reports
+var csvHeader = []string{"workItemId", "workItemType", "title", "value", "status", "reason", "newValue", "action", "error"}
+
+// CheckFormat returns an error unless format is a report format Write supports
+func CheckFormat(format string) error {
+	switch strings.ToLower(format) {
+	case "", "csv", "json":
+		return nil
+	default:
+		return fmt.Errorf("unknown report format '%s'", format)
+	}
+}
+
+// Write writes the report as "json" or "csv"
+func Write(w io.Writer, report *Report, format string) error {
+	switch strings.ToLower(format) {
+	case "", "csv":
+		return writeCSV(w, report)
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	default:
+		return fmt.Errorf("unknown report format '%s'", format)
+	}
+}
+
+// writeCSV writes one line per row of the report
+func writeCSV(w io.Writer, report *Report) error {
+	writer := csv.NewWriter(w)
+	writer.Write(csvHeader)
+	for _, row := range report.Rows {
+		writer.Write([]string{
+			strconv.Itoa(row.WorkItemID), row.WorkItemType, row.Title, row.Value,
+			row.Status, row.Reason, row.NewValue, row.Action, row.Error,
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// LoadMapping reads a mapping file: a JSON object of old to new values, or a
+// CSV file with the old value in its first column and the new one in its
+// second. A first CSV line of "from,to" is skipped.
+func LoadMapping(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping := make(map[string]string)
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		if err := json.Unmarshal(data, &mapping); err != nil {
+			return nil, fmt.Errorf("parsing mapping %s: %v", path, err)
+		}
+		return mapping, nil
+	}
+
+	reader := csv.NewReader(strings.NewReader(string(data)))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parsing mapping %s: %v", path, err)
+	}
+	for i, record := range records {
+		if len(record) < 2 {
+			return nil, fmt.Errorf("mapping %s line %d: want old and new value", path, i+1)
+		}
+		if i == 0 && strings.EqualFold(record[0], "from") && strings.EqualFold(record[1], "to") {
+			continue
+		}
+		mapping[record[0]] = record[1]
+	}
+	return mapping, nil
+}
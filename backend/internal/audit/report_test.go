@@ -0,0 +1,61 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadMapping(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"mapping.csv":  "from,to\nmarketing,CC001\n\"Sales, East\",CC003\n",
+		"mapping.json": `{"marketing": "CC001", "Sales, East": "CC003"}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		mapping, err := LoadMapping(path)
+		if err != nil {
+			t.Fatalf("LoadMapping(%s): %v", name, err)
+		}
+		if len(mapping) != 2 || mapping["marketing"] != "CC001" || mapping["Sales, East"] != "CC003" {
+			t.Errorf("LoadMapping(%s) = %v", name, mapping)
+		}
+	}
+
+	path := filepath.Join(dir, "short.csv")
+	os.WriteFile(path, []byte("marketing\n"), 0o644)
+	if _, err := LoadMapping(path); err == nil {
+		t.Error("LoadMapping accepted a line without a new value")
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	report := &Report{Rows: []Row{
+		{WorkItemID: 7, WorkItemType: "Task", Title: "Close, Q3", Value: "mkt", Status: "invalid", NewValue: "CC001", Action: ActionPlanned},
+	}}
+
+	var b strings.Builder
+	if err := Write(&b, report, "csv"); err != nil {
+		t.Fatal(err)
+	}
+	want := "workItemId,workItemType,title,value,status,reason,newValue,action,error\n" +
+		"7,Task,\"Close, Q3\",mkt,invalid,,CC001,planned,\n"
+	if b.String() != want {
+		t.Errorf("CSV =\n%s\nwant\n%s", b.String(), want)
+	}
+
+	if err := Write(&b, report, "xml"); err == nil {
+		t.Error("Write accepted an unknown format")
+	}
+	if err := CheckFormat("xml"); err == nil {
+		t.Error("CheckFormat accepted an unknown format")
+	}
+	if err := CheckFormat("JSON"); err != nil {
+		t.Errorf("CheckFormat(JSON) = %v", err)
+	}
+}
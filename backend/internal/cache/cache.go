@@ -1,49 +1,132 @@
 package cache
 
 import (
+	"fmt"
+	"path"
+	"snowflake-dropdown-api/internal/config"
 	"snowflake-dropdown-api/internal/models"
+	"strings"
 	"sync"
 	"time"
 )
 
+// Backend stores search responses with expiration
+type Backend interface {
+	// Get retrieves cached data, reporting false if it is missing or expired
+	Get(key string) (models.DropdownResponse, bool)
+	// Set stores data with the default expiration
+	Set(key string, value models.DropdownResponse)
+	// SetWithTTL stores data with a specific expiration
+	SetWithTTL(key string, value models.DropdownResponse, ttl time.Duration)
+	// Keys returns the keys of unexpired entries that start with prefix
+	Keys(prefix string) []string
+	// Entries returns the unexpired entries whose keys start with prefix
+	Entries(prefix string) map[string]models.DropdownResponse
+	// Delete removes the given keys
+	Delete(keys ...string)
+	// DeletePrefix removes every entry whose key starts with prefix
+	DeletePrefix(prefix string)
+	// Clear removes all cached data
+	Clear()
+	// Expiration returns the default cache expiration duration
+	Expiration() time.Duration
+	// SetExpiration sets the default cache expiration duration
+	SetExpiration(duration time.Duration)
+	// Close releases the backend's resources
+	Close() error
+}
+
+// Global cache instance
+var Instance Backend = NewMemory(1 * time.Hour) // Cache for 1 hour
+
+// New creates the cache backend selected by the cache settings
+func New(settings config.CacheSettings) (Backend, error) {
+	expiration := 1 * time.Hour
+	if settings.TTLMinutes > 0 {
+		expiration = time.Duration(settings.TTLMinutes) * time.Minute
+	}
+
+	switch strings.ToLower(settings.Backend) {
+	case "", "memory":
+		return NewMemory(expiration), nil
+	case "redis":
+		if settings.Redis == nil {
+			return nil, fmt.Errorf("cache backend redis requires cacheSettings.redis")
+		}
+		return NewRedis(settings.Redis, expiration)
+	case "disk":
+		if settings.Disk == nil {
+			return nil, fmt.Errorf("cache backend disk requires cacheSettings.disk")
+		}
+		return NewDisk(settings.Disk, expiration)
+	default:
+		return nil, fmt.Errorf("unknown cache backend: %s", settings.Backend)
+	}
+}
+
+// DeleteMatching removes the entries whose keys match a path.Match style
+// glob pattern and returns how many were removed
+func DeleteMatching(backend Backend, pattern string) (int, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return 0, fmt.Errorf("invalid key pattern %q: %w", pattern, err)
+	}
+
+	// Only keys sharing the pattern's literal prefix can match
+	prefix := pattern
+	if i := strings.IndexAny(pattern, `*?[\`); i >= 0 {
+		prefix = pattern[:i]
+	}
+
+	var matched []string
+	for _, key := range backend.Keys(prefix) {
+		if ok, _ := path.Match(pattern, key); ok {
+			matched = append(matched, key)
+		}
+	}
+	backend.Delete(matched...)
+	return len(matched), nil
+}
+
 // cacheItem represents a cached item with expiration
 type cacheItem struct {
 	value     models.DropdownResponse
 	expiresAt time.Time
 }
 
-// Cache stores the dropdown data with expiration
-type Cache struct {
+// Memory stores the dropdown data in a process-local map with expiration
+type Memory struct {
 	mu          sync.RWMutex
 	data        map[string]cacheItem
 	expiration  time.Duration
 	cleanupOnce sync.Once
 }
 
-// Global cache instance
-var Instance = &Cache{
-	data:       make(map[string]cacheItem),
-	expiration: 1 * time.Hour, // Cache for 1 hour
+// NewMemory creates an in-memory cache with the given default expiration
+func NewMemory(expiration time.Duration) *Memory {
+	return &Memory{
+		data:       make(map[string]cacheItem),
+		expiration: expiration,
+	}
 }
 
 // Get retrieves cached data
-func (c *Cache) Get(key string) (models.DropdownResponse, bool) {
+func (c *Memory) Get(key string) (models.DropdownResponse, bool) {
 	c.mu.RLock()
-	defer c.mu.RUnlock()
-
 	item, exists := c.data[key]
+	c.mu.RUnlock()
+
 	if !exists {
 		return models.DropdownResponse{}, false
 	}
 
 	// Check if expired
 	if time.Now().After(item.expiresAt) {
-		// Item expired, remove it
-		c.mu.RUnlock()
+		// Item expired, remove it unless it has been replaced meanwhile
 		c.mu.Lock()
-		delete(c.data, key)
+		if current, ok := c.data[key]; ok && time.Now().After(current.expiresAt) {
+			delete(c.data, key)
+		}
 		c.mu.Unlock()
-		c.mu.RLock()
 		return models.DropdownResponse{}, false
 	}
 
@@ -51,12 +134,22 @@ func (c *Cache) Get(key string) (models.DropdownResponse, bool) {
 }
 
 // Set stores data in cache
-func (c *Cache) Set(key string, value models.DropdownResponse) {
+func (c *Memory) Set(key string, value models.DropdownResponse) {
+	c.SetWithTTL(key, value, c.Expiration())
+}
+
+// SetWithTTL stores data in cache with a specific expiration
+func (c *Memory) SetWithTTL(key string, value models.DropdownResponse, ttl time.Duration) {
+	c.setUntil(key, value, time.Now().Add(ttl))
+}
+
+// setUntil stores data in cache until expiresAt
+func (c *Memory) setUntil(key string, value models.DropdownResponse, expiresAt time.Time) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.data[key] = cacheItem{
 		value:     value,
-		expiresAt: time.Now().Add(c.expiration),
+		expiresAt: expiresAt,
 	}
 
 	// Start cleanup goroutine once
@@ -65,22 +158,84 @@ func (c *Cache) Set(key string, value models.DropdownResponse) {
 	})
 }
 
+// Keys returns the keys of unexpired cached data that start with prefix
+func (c *Memory) Keys(prefix string) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+	var keys []string
+	for key, item := range c.data {
+		if strings.HasPrefix(key, prefix) && !now.After(item.expiresAt) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Entries returns the unexpired cached data whose keys start with prefix
+func (c *Memory) Entries(prefix string) map[string]models.DropdownResponse {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+	entries := make(map[string]models.DropdownResponse)
+	for key, item := range c.data {
+		if strings.HasPrefix(key, prefix) && !now.After(item.expiresAt) {
+			entries[key] = item.value
+		}
+	}
+	return entries
+}
+
+// Delete removes cached data by key
+func (c *Memory) Delete(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		delete(c.data, key)
+	}
+}
+
+// DeletePrefix removes all cached data whose key starts with prefix
+func (c *Memory) DeletePrefix(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.data {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.data, key)
+		}
+	}
+}
+
 // Clear removes all cached data
-func (c *Cache) Clear() {
+func (c *Memory) Clear() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.data = make(map[string]cacheItem)
 }
 
+// Expiration returns the default cache expiration duration
+func (c *Memory) Expiration() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.expiration
+}
+
 // SetExpiration sets the cache expiration duration
-func (c *Cache) SetExpiration(duration time.Duration) {
+func (c *Memory) SetExpiration(duration time.Duration) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.expiration = duration
 }
 
+// Close is a no-op for the in-memory cache
+func (c *Memory) Close() error {
+	return nil
+}
+
 // cleanupExpired removes expired items from cache periodically
-func (c *Cache) cleanupExpired() {
+func (c *Memory) cleanupExpired() {
 	ticker := time.NewTicker(30 * time.Minute) // Cleanup every 30 minutes
 	defer ticker.Stop()
 
@@ -0,0 +1,206 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/models"
+
+	"github.com/alicebob/miniredis/v2"
+	bolt "go.etcd.io/bbolt"
+)
+
+// newBackends returns an in-memory cache, a Redis cache backed by miniredis
+// and a disk cache in a temporary directory
+func newBackends(t *testing.T) (map[string]Backend, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	redisCache, err := New(config.CacheSettings{
+		Backend: "redis",
+		Redis:   &config.RedisConfig{Addr: server.Addr(), KeyPrefix: "test:"},
+	})
+	if err != nil {
+		t.Fatalf("New(redis) error = %v", err)
+	}
+	t.Cleanup(func() { redisCache.Close() })
+
+	diskCache, err := NewDisk(&config.DiskCacheConfig{Directory: t.TempDir()}, time.Hour)
+	if err != nil {
+		t.Fatalf("NewDisk() error = %v", err)
+	}
+	t.Cleanup(func() { diskCache.Close() })
+
+	return map[string]Backend{"memory": NewMemory(time.Hour), "redis": redisCache, "disk": diskCache}, server
+}
+
+func response(value string) models.DropdownResponse {
+	return models.DropdownResponse{
+		Data:     []models.DropdownItem{{Value: value, Label: value + " label"}},
+		Metadata: models.Metadata{RowCount: 1, Source: "cc"},
+	}
+}
+
+func TestBackendGetSet(t *testing.T) {
+	backends, _ := newBackends(t)
+	for name, c := range backends {
+		t.Run(name, func(t *testing.T) {
+			if _, found := c.Get("search:cc:a"); found {
+				t.Fatal("Get() found entry in empty cache")
+			}
+
+			c.Set("search:cc:a", response("CC001"))
+			got, found := c.Get("search:cc:a")
+			if !found {
+				t.Fatal("Get() did not find stored entry")
+			}
+			if len(got.Data) != 1 || got.Data[0].Value != "CC001" || got.Metadata.Source != "cc" {
+				t.Errorf("Get() = %+v", got)
+			}
+		})
+	}
+}
+
+func TestBackendDeletePrefix(t *testing.T) {
+	backends, _ := newBackends(t)
+	for name, c := range backends {
+		t.Run(name, func(t *testing.T) {
+			c.Set("search:cc:a", response("CC001"))
+			c.Set("search:cc:b", response("CC002"))
+			c.Set("search:ccx:a", response("X001"))
+			c.Set("search:wbs:a", response("W001"))
+
+			c.DeletePrefix("search:cc:")
+			for _, key := range []string{"search:cc:a", "search:cc:b"} {
+				if _, found := c.Get(key); found {
+					t.Errorf("%s still cached after DeletePrefix", key)
+				}
+			}
+			for _, key := range []string{"search:ccx:a", "search:wbs:a"} {
+				if _, found := c.Get(key); !found {
+					t.Errorf("%s removed by DeletePrefix of another data type", key)
+				}
+			}
+
+			c.Clear()
+			if _, found := c.Get("search:wbs:a"); found {
+				t.Error("entry still cached after Clear")
+			}
+		})
+	}
+}
+
+func TestBackendEntries(t *testing.T) {
+	backends, _ := newBackends(t)
+	for name, c := range backends {
+		t.Run(name, func(t *testing.T) {
+			c.Set("search:cc:a", response("CC001"))
+			c.Set("search:cc:b", response("CC002"))
+			c.Set("search:wbs:a", response("W001"))
+
+			entries := c.Entries("search:cc:")
+			if len(entries) != 2 || entries["search:cc:a"].Data[0].Value != "CC001" || entries["search:cc:b"].Data[0].Value != "CC002" {
+				t.Errorf("Entries() = %+v, want search:cc:a and search:cc:b", entries)
+			}
+		})
+	}
+}
+
+func TestRedisExpiryAndNamespace(t *testing.T) {
+	backends, server := newBackends(t)
+	c := backends["redis"]
+
+	server.Set("other:key", "untouched")
+	c.SetWithTTL("search:cc:a", response("CC001"), time.Minute)
+
+	if !server.Exists("test:search:cc:a") {
+		t.Fatalf("keys = %v, want test:search:cc:a", server.Keys())
+	}
+	if ttl := server.TTL("test:search:cc:a"); ttl != time.Minute {
+		t.Errorf("TTL = %v, want 1m", ttl)
+	}
+
+	server.FastForward(2 * time.Minute)
+	if _, found := c.Get("search:cc:a"); found {
+		t.Error("Get() found expired entry")
+	}
+
+	c.Clear()
+	if !server.Exists("other:key") {
+		t.Error("Clear removed a key outside the cache prefix")
+	}
+}
+
+func TestDiskReloadsUnexpiredEntries(t *testing.T) {
+	dir := t.TempDir()
+	c, err := New(config.CacheSettings{Backend: "disk", Disk: &config.DiskCacheConfig{Directory: dir}})
+	if err != nil {
+		t.Fatalf("New(disk) error = %v", err)
+	}
+	c.SetWithTTL("search:cc:a", response("CC001"), time.Hour)
+	c.SetWithTTL("search:cc:b", response("CC002"), time.Millisecond)
+	c.SetWithTTL("search:wbs:a", response("W001"), time.Hour)
+	c.DeletePrefix("search:wbs:")
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	reopened, err := NewDisk(&config.DiskCacheConfig{Directory: dir}, time.Hour)
+	if err != nil {
+		t.Fatalf("NewDisk() error = %v", err)
+	}
+	defer reopened.Close()
+
+	if got, found := reopened.Get("search:cc:a"); !found || got.Data[0].Value != "CC001" {
+		t.Errorf("Get(search:cc:a) = %+v, %v after restart", got, found)
+	}
+	for _, key := range []string{"search:cc:b", "search:wbs:a"} {
+		if _, found := reopened.Get(key); found {
+			t.Errorf("%s reloaded after restart", key)
+		}
+	}
+
+	reopened.Clear()
+	if loaded, err := reopened.load(); err != nil || loaded != 0 {
+		t.Errorf("load() after Clear = %d, %v, want 0 entries", loaded, err)
+	}
+}
+
+func TestDiskSweepRemovesExpiredEntries(t *testing.T) {
+	c, err := NewDisk(&config.DiskCacheConfig{Directory: t.TempDir()}, time.Hour)
+	if err != nil {
+		t.Fatalf("NewDisk() error = %v", err)
+	}
+	defer c.Close()
+
+	c.SetWithTTL("search:cc:a", response("CC001"), time.Hour)
+	c.SetWithTTL("search:cc:b", response("CC002"), time.Millisecond)
+	c.flush()
+	time.Sleep(5 * time.Millisecond)
+	c.sweep()
+
+	var keys []string
+	c.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(diskBucket).ForEach(func(k, _ []byte) error {
+			keys = append(keys, string(k))
+			return nil
+		})
+	})
+	if len(keys) != 1 || keys[0] != "search:cc:a" {
+		t.Errorf("keys in file after sweep = %v, want search:cc:a", keys)
+	}
+}
+
+func TestNewRejectsUnknownBackend(t *testing.T) {
+	if _, err := New(config.CacheSettings{Backend: "memcached"}); err == nil {
+		t.Error("New() error = nil for unknown backend")
+	}
+	if _, err := New(config.CacheSettings{Backend: "redis"}); err == nil {
+		t.Error("New() error = nil for redis without settings")
+	}
+	if _, err := New(config.CacheSettings{Backend: "disk"}); err == nil {
+		t.Error("New() error = nil for disk without settings")
+	}
+}
@@ -0,0 +1,325 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/models"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	diskFileName      = "cache.db"
+	diskSweepInterval = 30 * time.Minute
+)
+
+var diskBucket = []byte("entries")
+
+// diskEntry is the stored form of a cached response
+type diskEntry struct {
+	Value     models.DropdownResponse `json:"value"`
+	ExpiresAt time.Time               `json:"expiresAt"`
+}
+
+// Disk is an in-memory cache that writes every entry through to a bbolt file,
+// so unexpired entries survive restarts. Reads are served from memory; writes
+// are queued and stored by a background writer, one transaction per batch.
+// Disk errors are logged and leave the in-memory cache working. Expired
+// entries are dropped from the file when it is loaded and by a periodic sweep.
+type Disk struct {
+	*Memory
+	db *bolt.DB
+
+	mu      sync.Mutex
+	pending map[string][]byte // encoded entries not yet written, by key
+	flushMu sync.Mutex        // orders batch writes with deletes
+	wake    chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+}
+
+// NewDisk opens or creates the cache file in the configured directory and
+// loads its unexpired entries
+func NewDisk(cfg *config.DiskCacheConfig, expiration time.Duration) (*Disk, error) {
+	if cfg.Directory == "" {
+		return nil, fmt.Errorf("disk cache requires a directory")
+	}
+	if err := os.MkdirAll(cfg.Directory, 0o755); err != nil {
+		return nil, fmt.Errorf("create cache directory: %w", err)
+	}
+
+	path := filepath.Join(cfg.Directory, diskFileName)
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open cache file %s: %w", path, err)
+	}
+
+	c := &Disk{
+		Memory:  NewMemory(expiration),
+		db:      db,
+		pending: make(map[string][]byte),
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	loaded, err := c.load()
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("load cache file %s: %w", path, err)
+	}
+	go c.writer()
+
+	log.Printf("Loaded %d cached searches from %s", loaded, path)
+	return c, nil
+}
+
+// load copies unexpired entries into memory and deletes expired ones
+func (c *Disk) load() (int, error) {
+	loaded := 0
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(diskBucket)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		var expired [][]byte
+		err = bucket.ForEach(func(k, v []byte) error {
+			var entry diskEntry
+			if err := json.Unmarshal(v, &entry); err != nil || now.After(entry.ExpiresAt) {
+				expired = append(expired, k)
+				return nil
+			}
+			c.Memory.setUntil(string(k), entry.Value, entry.ExpiresAt)
+			loaded++
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, k := range expired {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return loaded, err
+}
+
+// Set stores data in cache
+func (c *Disk) Set(key string, value models.DropdownResponse) {
+	c.SetWithTTL(key, value, c.Expiration())
+}
+
+// SetWithTTL stores data in cache with a specific expiration
+func (c *Disk) SetWithTTL(key string, value models.DropdownResponse, ttl time.Duration) {
+	expiresAt := time.Now().Add(ttl)
+	c.Memory.setUntil(key, value, expiresAt)
+
+	data, err := json.Marshal(diskEntry{Value: value, ExpiresAt: expiresAt})
+	if err != nil {
+		log.Printf("Disk cache encode %s failed: %v", key, err)
+		return
+	}
+
+	c.mu.Lock()
+	c.pending[key] = data
+	c.mu.Unlock()
+
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// writer stores queued entries until the cache is closed, and sweeps
+// expired entries from the file. Entries queued while a batch is being
+// written go into the next batch.
+func (c *Disk) writer() {
+	defer close(c.stopped)
+	ticker := time.NewTicker(diskSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.wake:
+			c.flush()
+		case <-ticker.C:
+			c.sweep()
+		case <-c.done:
+			c.flush()
+			return
+		}
+	}
+}
+
+// flush writes the queued entries in one transaction
+func (c *Disk) flush() {
+	c.flushMu.Lock()
+	defer c.flushMu.Unlock()
+
+	c.mu.Lock()
+	batch := c.pending
+	c.pending = make(map[string][]byte)
+	c.mu.Unlock()
+	if len(batch) == 0 {
+		return
+	}
+
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(diskBucket)
+		for key, data := range batch {
+			if err := bucket.Put([]byte(key), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Disk cache write of %d entries failed: %v", len(batch), err)
+	}
+}
+
+// sweep deletes the expired and unreadable entries from the file
+func (c *Disk) sweep() {
+	c.flushMu.Lock()
+	defer c.flushMu.Unlock()
+
+	var removed int
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(diskBucket)
+		now := time.Now()
+
+		var expired [][]byte
+		err := bucket.ForEach(func(k, v []byte) error {
+			var entry diskEntry
+			if err := json.Unmarshal(v, &entry); err != nil || now.After(entry.ExpiresAt) {
+				expired = append(expired, k)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, k := range expired {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		removed = len(expired)
+		return nil
+	})
+	if err != nil {
+		log.Printf("Disk cache sweep failed: %v", err)
+		return
+	}
+	if removed > 0 {
+		log.Printf("Disk cache sweep removed %d expired entries", removed)
+	}
+}
+
+// dropPending removes queued entries whose key matches so a delete is not
+// undone by a later batch. The caller holds flushMu.
+func (c *Disk) dropPending(match func(key string) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.pending {
+		if match(key) {
+			delete(c.pending, key)
+		}
+	}
+}
+
+// Delete removes cached data by key
+func (c *Disk) Delete(keys ...string) {
+	c.Memory.Delete(keys...)
+
+	c.flushMu.Lock()
+	defer c.flushMu.Unlock()
+	deleted := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		deleted[key] = true
+	}
+	c.dropPending(func(key string) bool { return deleted[key] })
+
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(diskBucket)
+		for _, key := range keys {
+			if err := bucket.Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Disk cache delete failed: %v", err)
+	}
+}
+
+// DeletePrefix removes all cached data whose key starts with prefix
+func (c *Disk) DeletePrefix(prefix string) {
+	c.Memory.DeletePrefix(prefix)
+
+	c.flushMu.Lock()
+	defer c.flushMu.Unlock()
+	c.dropPending(func(key string) bool { return strings.HasPrefix(key, prefix) })
+
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(diskBucket)
+		p := []byte(prefix)
+
+		var keys [][]byte
+		cursor := bucket.Cursor()
+		for k, _ := cursor.Seek(p); k != nil && bytes.HasPrefix(k, p); k, _ = cursor.Next() {
+			keys = append(keys, k)
+		}
+		for _, k := range keys {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Disk cache delete %s failed: %v", prefix, err)
+	}
+}
+
+// Clear removes all cached data
+func (c *Disk) Clear() {
+	c.Memory.Clear()
+
+	c.flushMu.Lock()
+	defer c.flushMu.Unlock()
+	c.dropPending(func(string) bool { return true })
+
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(diskBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucket(diskBucket)
+		return err
+	})
+	if err != nil {
+		log.Printf("Disk cache clear failed: %v", err)
+	}
+}
+
+// Close writes the queued entries and closes the cache file
+func (c *Disk) Close() error {
+	c.once.Do(func() { close(c.done) })
+	<-c.stopped
+	return c.db.Close()
+}
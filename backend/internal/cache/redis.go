@@ -0,0 +1,269 @@
+package cache
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultKeyPrefix = "dropdown:"
+	redisOpTimeout   = 2 * time.Second
+	redisScanCount   = 500
+)
+
+// Redis stores the dropdown data in a Redis server shared between replicas.
+// Every key is stored under the configured key prefix, and expiry is left to
+// Redis. Redis errors are logged and treated as cache misses.
+type Redis struct {
+	client     *redis.Client
+	prefix     string
+	mu         sync.RWMutex
+	expiration time.Duration
+}
+
+// NewRedis connects to the configured Redis server
+func NewRedis(cfg *config.RedisConfig, expiration time.Duration) (*Redis, error) {
+	addr := config.ResolveValue(cfg.Addr)
+	if addr == "" {
+		return nil, fmt.Errorf("redis cache requires an addr")
+	}
+
+	options := &redis.Options{
+		Addr:     addr,
+		Username: config.ResolveValue(cfg.Username),
+		Password: config.ResolveValue(cfg.Password),
+		DB:       cfg.DB,
+	}
+	if cfg.TLS {
+		options.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	prefix := cfg.KeyPrefix
+	if prefix == "" {
+		prefix = defaultKeyPrefix
+	}
+
+	client := redis.NewClient(options)
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("connect to redis at %s: %w", addr, err)
+	}
+
+	return &Redis{client: client, prefix: prefix, expiration: expiration}, nil
+}
+
+// Get retrieves cached data
+func (c *Redis) Get(key string) (models.DropdownResponse, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+
+	data, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			log.Printf("Redis cache get %s failed: %v", key, err)
+		}
+		return models.DropdownResponse{}, false
+	}
+
+	var value models.DropdownResponse
+	if err := json.Unmarshal(data, &value); err != nil {
+		log.Printf("Redis cache entry %s is invalid: %v", key, err)
+		return models.DropdownResponse{}, false
+	}
+	return value, true
+}
+
+// Set stores data in cache
+func (c *Redis) Set(key string, value models.DropdownResponse) {
+	c.SetWithTTL(key, value, c.Expiration())
+}
+
+// SetWithTTL stores data in cache with a specific expiration
+func (c *Redis) SetWithTTL(key string, value models.DropdownResponse, ttl time.Duration) {
+	// Redis treats a zero TTL as no expiry; such entries would already be expired
+	if ttl <= 0 {
+		return
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("Redis cache encode %s failed: %v", key, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+	if err := c.client.Set(ctx, c.prefix+key, data, ttl).Err(); err != nil {
+		log.Printf("Redis cache set %s failed: %v", key, err)
+	}
+}
+
+// Keys returns the keys of cached data that start with prefix
+func (c *Redis) Keys(prefix string) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	pattern := escapePattern(c.prefix+prefix) + "*"
+	iter := c.client.Scan(ctx, 0, pattern, redisScanCount).Iterator()
+
+	// SCAN may return a key more than once
+	seen := make(map[string]bool)
+	var keys []string
+	for iter.Next(ctx) {
+		key := strings.TrimPrefix(iter.Val(), c.prefix)
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		log.Printf("Redis cache scan %s failed: %v", pattern, err)
+	}
+	return keys
+}
+
+// Entries returns the cached data whose keys start with prefix. Keys are
+// scanned in batches and each batch is read with a single MGET.
+func (c *Redis) Entries(prefix string) map[string]models.DropdownResponse {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	pattern := escapePattern(c.prefix+prefix) + "*"
+	iter := c.client.Scan(ctx, 0, pattern, redisScanCount).Iterator()
+
+	entries := make(map[string]models.DropdownResponse)
+	var batch []string
+	for iter.Next(ctx) {
+		batch = append(batch, iter.Val())
+		if len(batch) >= redisScanCount {
+			c.readEntries(ctx, batch, entries)
+			batch = batch[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		log.Printf("Redis cache scan %s failed: %v", pattern, err)
+	}
+	c.readEntries(ctx, batch, entries)
+	return entries
+}
+
+// readEntries reads a batch of prefixed keys into entries, skipping keys that
+// expired since they were scanned
+func (c *Redis) readEntries(ctx context.Context, keys []string, entries map[string]models.DropdownResponse) {
+	if len(keys) == 0 {
+		return
+	}
+	values, err := c.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		log.Printf("Redis cache read failed: %v", err)
+		return
+	}
+	for i, raw := range values {
+		data, ok := raw.(string)
+		if !ok {
+			continue
+		}
+		key := strings.TrimPrefix(keys[i], c.prefix)
+		var value models.DropdownResponse
+		if err := json.Unmarshal([]byte(data), &value); err != nil {
+			log.Printf("Redis cache entry %s is invalid: %v", key, err)
+			continue
+		}
+		entries[key] = value
+	}
+}
+
+// Delete removes cached data by key
+func (c *Redis) Delete(keys ...string) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.prefix + key
+	}
+	c.delete(ctx, prefixed)
+}
+
+// DeletePrefix removes all cached data whose key starts with prefix
+func (c *Redis) DeletePrefix(prefix string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	pattern := escapePattern(c.prefix+prefix) + "*"
+	iter := c.client.Scan(ctx, 0, pattern, redisScanCount).Iterator()
+
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) >= redisScanCount {
+			c.delete(ctx, keys)
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		log.Printf("Redis cache scan %s failed: %v", pattern, err)
+	}
+	c.delete(ctx, keys)
+}
+
+// delete removes a batch of keys
+func (c *Redis) delete(ctx context.Context, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+	if err := c.client.Del(ctx, keys...).Err(); err != nil {
+		log.Printf("Redis cache delete failed: %v", err)
+	}
+}
+
+// Clear removes all cached data under the key prefix
+func (c *Redis) Clear() {
+	c.DeletePrefix("")
+}
+
+// Expiration returns the default cache expiration duration
+func (c *Redis) Expiration() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.expiration
+}
+
+// SetExpiration sets the cache expiration duration
+func (c *Redis) SetExpiration(duration time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expiration = duration
+}
+
+// Close closes the Redis connection pool
+func (c *Redis) Close() error {
+	return c.client.Close()
+}
+
+// escapePattern escapes glob metacharacters for use in a SCAN MATCH pattern
+func escapePattern(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -2,30 +2,293 @@ package config
 
 // DataTypeConfig represents a configurable data type
 type DataTypeConfig struct {
-	ID           string   `json:"id"`
-	Name         string   `json:"name"`
-	Description  string   `json:"description"`
+	ID           string            `json:"id"`
+	Name         string            `json:"name"`
+	Description  string            `json:"description"`
+	Provider     string            `json:"provider,omitempty"` // defaults to "snowflake"
+	Connection   *ConnectionConfig `json:"connection,omitempty"`
+	File         *FileSourceConfig `json:"file,omitempty"`
+	REST         *RESTConfig       `json:"rest,omitempty"`
+	GraphQL      *GraphQLConfig    `json:"graphql,omitempty"`
+	Query        string            `json:"query"`
+	LookupQuery  string            `json:"lookupQuery,omitempty"` // selects the item whose value is bound to its one placeholder
+	Table        string            `json:"table,omitempty"`       // generates the query from the structured fields below instead of Query
+	SearchFields []string          `json:"searchFields"`
+	Icon         string            `json:"icon"`
+	Enabled      bool              `json:"enabled"`
+
+	// Structured query form, used when Table is set. Column names may be
+	// qualified (schema.table.column) but not quoted.
+	ValueColumn   string         `json:"valueColumn,omitempty"`
+	LabelColumn   string         `json:"labelColumn,omitempty"`
+	LabelTemplate string         `json:"labelTemplate,omitempty"` // e.g. "{code} - {name}", overrides LabelColumn
+	OrderBy       string         `json:"orderBy,omitempty"`       // comma-separated columns with optional ASC/DESC, defaults to ValueColumn
+	Filters       []FilterConfig `json:"filters,omitempty"`
+
+	// Attributes are extra columns returned with each item
+	Attributes []AttributeConfig `json:"attributes,omitempty"`
+
+	// Params are named context parameters, such as the value selected in
+	// another field, passed with each search to narrow the results
+	Params []ParamConfig `json:"params,omitempty"`
+	// ContextValues holds the parameter values of one search, see WithContext
+	ContextValues map[string]string `json:"-"`
+
+	Snapshot *SnapshotConfig `json:"snapshot,omitempty"`
+	Schedule *ScheduleConfig `json:"schedule,omitempty"`
+	Fuzzy    *FuzzyConfig    `json:"fuzzy,omitempty"`
+	Status   *StatusConfig   `json:"status,omitempty"`
+
+	// CacheTTLMinutes overrides cacheSettings.ttlMinutes; -1 disables caching for this type
+	CacheTTLMinutes int `json:"cacheTtlMinutes,omitempty"`
+	// WarmPrefixes are searched, after the empty term, when the cache is warmed
+	WarmPrefixes []string `json:"warmPrefixes,omitempty"`
+}
+
+// FilterConfig is a fixed condition applied to a structured query
+type FilterConfig struct {
+	Column   string      `json:"column"`
+	Operator string      `json:"operator,omitempty"` // =, !=, <, <=, >, >=, like, in, isNull or notNull; defaults to =
+	Value    interface{} `json:"value,omitempty"`    // an array for "in"
+}
+
+// AttributeConfig describes an extra column returned in each item's
+// attributes. Hand-written SQL queries select it under its name; generated
+// queries select Column. File data types read Column from each record,
+// GraphQL data types the field path Column of each result object and REST
+// data types the JSONPath Column from the response.
+type AttributeConfig struct {
+	Name    string `json:"name"`              // key in the item attributes, e.g. owner
+	Column  string `json:"column,omitempty"`  // defaults to Name
+	Label   string `json:"label,omitempty"`   // display name, defaults to Name
+	Display string `json:"display,omitempty"` // "text" (default) for secondary text or "badge"
+}
+
+// ColumnName returns the column an attribute is read from
+func (a AttributeConfig) ColumnName() string {
+	if a.Column == "" {
+		return a.Name
+	}
+	return a.Column
+}
+
+// Attribute display styles
+const (
+	DisplayText  = "text"
+	DisplayBadge = "badge"
+)
+
+// Cascade behaviors of a context parameter's source field
+const (
+	CascadeClear   = "clear"   // clear the selected value when the source changes
+	CascadeRefresh = "refresh" // keep the selected value and search again
+	CascadeDisable = "disable" // disable the field until the source has a value
+)
+
+// ParamConfig declares a context parameter. Structured data types filter on
+// Column; hand-written queries reference the parameter as {name}, which is
+// bound as a placeholder.
+type ParamConfig struct {
+	Name      string `json:"name"`                // query parameter name, e.g. costCenter
+	Column    string `json:"column,omitempty"`    // structured data types: only rows with this column equal to the value
+	DependsOn string `json:"dependsOn,omitempty"` // data type whose selected value is passed, e.g. cc
+	Required  bool   `json:"required,omitempty"`  // reject searches without a value
+	Cascade   string `json:"cascade,omitempty"`   // "clear" (default), "refresh" or "disable"
+}
+
+// WithContext returns a copy of the data type with the values of its
+// context parameters bound for one search
+func (dt *DataTypeConfig) WithContext(values map[string]string) *DataTypeConfig {
+	bound := *dt
+	bound.ContextValues = values
+	return &bound
+}
+
+// ConnectionConfig holds connection and pool settings for SQL providers.
+// String values may reference environment variables as "env:NAME".
+type ConnectionConfig struct {
+	DSN                    string `json:"dsn,omitempty"`
+	Path                   string `json:"path,omitempty"` // file-based databases
+	Host                   string `json:"host,omitempty"`
+	Port                   int    `json:"port,omitempty"`
+	User                   string `json:"user,omitempty"`
+	Password               string `json:"password,omitempty"`
+	Database               string `json:"database,omitempty"`
+	SSLMode                string `json:"sslMode,omitempty"`
+	MaxOpenConns           int    `json:"maxOpenConns,omitempty"`
+	MaxIdleConns           int    `json:"maxIdleConns,omitempty"`
+	ConnMaxLifetimeMinutes int    `json:"connMaxLifetimeMinutes,omitempty"`
+}
+
+// SnapshotConfig enables serving a data type from an in-memory copy of its
+// full dataset, refreshed on a schedule
+type SnapshotConfig struct {
+	Enabled        bool   `json:"enabled"`
+	Query          string `json:"query,omitempty"`          // full dataset query without placeholders, defaults to the search query with an empty term
+	RefreshMinutes int    `json:"refreshMinutes,omitempty"` // defaults to 60
+	MatchMode      string `json:"matchMode,omitempty"`      // "substring" (default) or "prefix"
+}
+
+// FuzzyConfig enables typo-tolerant search: candidate rows are re-ranked by
+// their similarity to the search term instead of filtered with LIKE
+type FuzzyConfig struct {
+	Enabled        bool    `json:"enabled"`
+	CandidateQuery string  `json:"candidateQuery,omitempty"` // query without placeholders returning the candidates, defaults to the dataset query
+	MinScore       float64 `json:"minScore,omitempty"`       // lowest relevance returned, defaults to 0.6
+}
+
+// StatusConfig lets value validation tell inactive items from unknown values
+type StatusConfig struct {
+	// Query selects value, label and status for the value bound to its one
+	// placeholder, including inactive items
 	Query        string   `json:"query"`
-	SearchFields []string `json:"searchFields"`
-	Icon         string   `json:"icon"`
-	Enabled      bool     `json:"enabled"`
+	ActiveValues []string `json:"activeValues,omitempty"` // statuses of active items, compared case-insensitively; defaults to active, true, 1, y and yes
+}
+
+// ScheduleConfig runs a data type's cache warm-up, or its snapshot refresh in
+// snapshot mode, on a cron schedule
+type ScheduleConfig struct {
+	Cron          string `json:"cron"`                    // standard 5-field cron expression, optionally prefixed with CRON_TZ=<zone>
+	JitterSeconds int    `json:"jitterSeconds,omitempty"` // maximum random delay per run, defaults to 60; -1 disables
+}
+
+// FileSourceConfig describes a CSV or JSON file served by the file provider
+type FileSourceConfig struct {
+	Path          string `json:"path"`
+	Format        string `json:"format,omitempty"` // "csv" or "json", defaults to the file extension
+	ValueColumn   string `json:"valueColumn"`
+	LabelColumn   string `json:"labelColumn"`
+	ReloadSeconds int    `json:"reloadSeconds,omitempty"` // how often to check for changes, defaults to 30
+}
+
+// RESTConfig describes an HTTP API served by the rest provider.
+// "{searchTerm}", "{maxResults}" and "{name}" for context parameters are
+// substituted in the endpoint and params.
+type RESTConfig struct {
+	BaseURL        string                 `json:"baseUrl"`
+	Endpoint       string                 `json:"endpoint"`
+	Method         string                 `json:"method,omitempty"` // GET (default) or POST with params as a JSON body
+	Auth           *AuthConfig            `json:"auth,omitempty"`
+	Headers        map[string]string      `json:"headers,omitempty"`
+	Params         map[string]interface{} `json:"params,omitempty"`
+	Mapping        MappingConfig          `json:"mapping"`
+	TimeoutSeconds int                    `json:"timeoutSeconds,omitempty"` // per attempt, defaults to 10
+	Retries        int                    `json:"retries,omitempty"`
+}
+
+// GraphQLConfig describes a GraphQL API served by the graphql provider.
+// The search term is passed as the $search variable and context parameters
+// as variables of their name.
+type GraphQLConfig struct {
+	Endpoint       string                 `json:"endpoint"`
+	Query          string                 `json:"query"`
+	Variables      map[string]interface{} `json:"variables,omitempty"`
+	ResultPath     string                 `json:"resultPath"` // JSONPath selecting the result objects, e.g. $.data.vendors[*]
+	ValueField     string                 `json:"valueField"`
+	LabelField     string                 `json:"labelField"`
+	Auth           *AuthConfig            `json:"auth,omitempty"`
+	Headers        map[string]string      `json:"headers,omitempty"`
+	TimeoutSeconds int                    `json:"timeoutSeconds,omitempty"` // per attempt, defaults to 10
+	Retries        int                    `json:"retries,omitempty"`
+}
+
+// AuthConfig holds credentials for upstream APIs.
+// Values may reference environment variables as "env:NAME".
+type AuthConfig struct {
+	Type     string `json:"type"` // "bearer", "basic" or "header"
+	Token    string `json:"token,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Header   string `json:"header,omitempty"` // header name for type "header"
+}
+
+// MappingConfig holds JSONPath expressions selecting item values and labels.
+// With Results set, Value, Label and attribute columns are rooted at each
+// result object rather than the response.
+type MappingConfig struct {
+	Results string `json:"results,omitempty"` // e.g. $.data[*]
+	Value   string `json:"value"`
+	Label   string `json:"label"`
 }
 
 // Config represents the application configuration
 type Config struct {
 	DataTypes       []DataTypeConfig `json:"dataTypes"`
 	DefaultDataType string           `json:"defaultDataType"`
-	CacheSettings   struct {
-		Enabled    bool `json:"enabled"`
-		TTLMinutes int  `json:"ttlMinutes"`
-	} `json:"cacheSettings"`
-	SearchSettings struct {
+	CacheSettings   CacheSettings    `json:"cacheSettings"`
+	Validation      Validation       `json:"validation"`
+	ADO             *ADOConfig       `json:"ado,omitempty"`
+	ServiceHooks    *ServiceHooks    `json:"serviceHooks,omitempty"`
+	SearchSettings  struct {
 		MinSearchLength int `json:"minSearchLength"`
 		DebounceMs      int `json:"debounceMs"`
 		MaxResults      int `json:"maxResults"`
 	} `json:"searchSettings"`
 }
 
+// CacheSettings selects the search cache backend and its default TTL
+type CacheSettings struct {
+	Enabled    bool             `json:"enabled"`
+	TTLMinutes int              `json:"ttlMinutes"`
+	Backend    string           `json:"backend,omitempty"` // "memory" (default), "redis" or "disk"
+	Redis      *RedisConfig     `json:"redis,omitempty"`
+	Disk       *DiskCacheConfig `json:"disk,omitempty"`
+
+	// MaxStaleMinutes keeps entries this long past their TTL to serve when the
+	// provider fails, or while refreshing with StaleWhileRevalidate; 0 disables both
+	MaxStaleMinutes      int  `json:"maxStaleMinutes,omitempty"`
+	StaleWhileRevalidate bool `json:"staleWhileRevalidate,omitempty"`
+}
+
+// Validation controls the startup checks of data type queries
+type Validation struct {
+	// DescribeQueries has providers run each query against the database,
+	// without reading rows, to check the columns it returns
+	DescribeQueries bool   `json:"describeQueries,omitempty"`
+	OnError         string `json:"onError,omitempty"` // "fail" (default) refuses to start, "disable" disables the data type
+}
+
+// ADOConfig holds the connection to the Azure DevOps REST API
+type ADOConfig struct {
+	BaseURL        string      `json:"baseUrl"` // organization URL, e.g. https://dev.azure.com/contoso
+	Auth           *AuthConfig `json:"auth,omitempty"`
+	TimeoutSeconds int         `json:"timeoutSeconds,omitempty"` // per attempt, defaults to 10
+	Retries        int         `json:"retries,omitempty"`
+}
+
+// ServiceHooks configures the receiver of Azure DevOps work item events
+type ServiceHooks struct {
+	// Secret must be sent as the basic auth password or X-Hook-Secret header;
+	// may be "env:NAME"
+	Secret  string      `json:"secret,omitempty"`
+	Fields  []FieldRule `json:"fields"`
+	Comment bool        `json:"comment,omitempty"` // comment on work items with invalid values
+	Tag     string      `json:"tag,omitempty"`     // tag added to work items with invalid values
+}
+
+// FieldRule binds a work item field to the data type its values must belong to
+type FieldRule struct {
+	Field         string   `json:"field"` // reference name, e.g. Custom.CostCenter
+	DataType      string   `json:"dataType"`
+	WorkItemTypes []string `json:"workItemTypes,omitempty"` // defaults to every type
+}
+
+// RedisConfig holds connection settings for the redis cache backend.
+// String values may reference environment variables as "env:NAME".
+type RedisConfig struct {
+	Addr      string `json:"addr"`
+	Username  string `json:"username,omitempty"`
+	Password  string `json:"password,omitempty"`
+	DB        int    `json:"db,omitempty"`
+	TLS       bool   `json:"tls,omitempty"`
+	KeyPrefix string `json:"keyPrefix,omitempty"` // prepended to every key, defaults to "dropdown:"
+}
+
+// DiskCacheConfig holds settings for the persistent disk cache backend
+type DiskCacheConfig struct {
+	Directory string `json:"directory"` // holds cache.db, created if missing
+}
+
 // SecurityConfig holds security settings
 type SecurityConfig struct {
 	APIKeyEnabled bool
@@ -33,4 +296,4 @@ type SecurityConfig struct {
 	JWTEnabled    bool
 	JWTSecret     string
 	IPWhitelist   []string
-}
\ No newline at end of file
+}
@@ -99,6 +99,22 @@ func LoadSecurityConfig() SecurityConfig {
 	return config
 }
 
+// ResolveValue expands "env:NAME" references to the named environment variable
+func ResolveValue(value string) string {
+	if name, ok := strings.CutPrefix(value, "env:"); ok {
+		return os.Getenv(name)
+	}
+	return value
+}
+
+// GetMaxResults returns the configured maximum number of search results
+func GetMaxResults() int {
+	if AppConfig != nil && AppConfig.SearchSettings.MaxResults > 0 {
+		return AppConfig.SearchSettings.MaxResults
+	}
+	return 100
+}
+
 // getDefaultConfig returns the default configuration
 func getDefaultConfig() *Config {
 	return &Config{
@@ -123,10 +139,7 @@ func getDefaultConfig() *Config {
 			},
 		},
 		DefaultDataType: "cc",
-		CacheSettings: struct {
-			Enabled    bool `json:"enabled"`
-			TTLMinutes int  `json:"ttlMinutes"`
-		}{
+		CacheSettings: CacheSettings{
 			Enabled:    true,
 			TTLMinutes: 60,
 		},
@@ -157,6 +170,18 @@ func GetDataTypeConfig(dataType string) (*DataTypeConfig, error) {
 	return nil, fmt.Errorf("data type '%s' not found or disabled", dataType)
 }
 
+// DisableDataType disables the data type with the given ID
+func DisableDataType(dataType string) {
+	if AppConfig == nil {
+		return
+	}
+	for i := range AppConfig.DataTypes {
+		if AppConfig.DataTypes[i].ID == dataType {
+			AppConfig.DataTypes[i].Enabled = false
+		}
+	}
+}
+
 // GetEnabledDataTypes returns all enabled data types
 func GetEnabledDataTypes() []DataTypeConfig {
 	if AppConfig == nil {
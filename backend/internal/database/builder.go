@@ -0,0 +1,439 @@
+package database
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"snowflake-dropdown-api/internal/config"
+)
+
+// textTypes maps providers to the type columns are cast to in label templates
+var textTypes = map[string]string{
+	"snowflake": "VARCHAR",
+	"postgres":  "TEXT",
+	"sqlite":    "TEXT",
+}
+
+// templateField matches a {column} reference in a label template
+var templateField = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// filterOperators maps the supported filter operators to SQL
+var filterOperators = map[string]string{
+	"=":       "=",
+	"!=":      "<>",
+	"<":       "<",
+	"<=":      "<=",
+	">":       ">",
+	">=":      ">=",
+	"like":    "LIKE",
+	"in":      "IN",
+	"isnull":  "IS NULL",
+	"notnull": "IS NOT NULL",
+}
+
+// Structured reports whether a data type's query is generated from its
+// table and column settings
+func Structured(dtConfig *config.DataTypeConfig) bool {
+	return dtConfig.Table != ""
+}
+
+// BuildStructuredQuery generates a search query from a data type's table and
+// column settings, limited to the maximum result count
+func BuildStructuredQuery(dtConfig *config.DataTypeConfig, searchTerm string) (string, []interface{}) {
+	return buildStructured(dtConfig, searchTerm, false, config.GetMaxResults())
+}
+
+// BuildStructuredDatasetQuery generates a query returning every row of a
+// structured data type, with its search fields as extra columns
+func BuildStructuredDatasetQuery(dtConfig *config.DataTypeConfig) (string, []interface{}) {
+	return buildStructured(dtConfig, "", true, 0)
+}
+
+// buildStructured assembles the SELECT statement. A limit of 0 means no limit.
+func buildStructured(dtConfig *config.DataTypeConfig, searchTerm string, withFields bool, limit int) (string, []interface{}) {
+	columns := []string{
+		dtConfig.ValueColumn + " AS value",
+		labelExpression(dtConfig) + " AS label",
+	}
+	columns = append(columns, attributeColumns(dtConfig)...)
+	if withFields {
+		columns = append(columns, dtConfig.SearchFields...)
+	}
+
+	conditions, params := structuredConditions(dtConfig, searchTerm)
+
+	orderBy := dtConfig.OrderBy
+	if orderBy == "" {
+		orderBy = dtConfig.ValueColumn
+	}
+
+	query := "SELECT " + strings.Join(columns, ", ") +
+		" FROM " + dtConfig.Table +
+		" WHERE " + strings.Join(conditions, " AND ") +
+		" ORDER BY " + orderBy
+	if limit > 0 {
+		query += " LIMIT " + strconv.Itoa(limit)
+	}
+	return query, params
+}
+
+// BuildLookupQuery returns the query selecting the item with a given value:
+// the data type's lookup query, or one generated for structured data types.
+// ok is false if the data type has neither.
+func BuildLookupQuery(dtConfig *config.DataTypeConfig, value string) (query string, params []interface{}, ok bool) {
+	if dtConfig.LookupQuery != "" {
+		return dtConfig.LookupQuery, []interface{}{value}, true
+	}
+	if !Structured(dtConfig) {
+		return "", nil, false
+	}
+
+	conditions, params := structuredConditions(dtConfig, "")
+	conditions = append(conditions, dtConfig.ValueColumn+" = ?")
+	params = append(params, value)
+
+	columns := append([]string{
+		dtConfig.ValueColumn + " AS value",
+		labelExpression(dtConfig) + " AS label",
+	}, attributeColumns(dtConfig)...)
+
+	query = "SELECT " + strings.Join(columns, ", ") +
+		" FROM " + dtConfig.Table +
+		" WHERE " + strings.Join(conditions, " AND ")
+	return query, params, true
+}
+
+// BuildBatchLookupQuery returns one query selecting the items with any of
+// the given values: the data type's lookup query run once per value in a
+// UNION ALL, or for structured data types an IN condition. ok is false if
+// the data type has neither.
+func BuildBatchLookupQuery(dtConfig *config.DataTypeConfig, values []string) (query string, params []interface{}, ok bool) {
+	if dtConfig.LookupQuery != "" {
+		query, params = UnionPerValue(dtConfig.LookupQuery, values)
+		return query, params, true
+	}
+	if !Structured(dtConfig) {
+		return "", nil, false
+	}
+
+	conditions, params := structuredConditions(dtConfig, "")
+	placeholders := make([]string, len(values))
+	for i, value := range values {
+		placeholders[i] = "?"
+		params = append(params, value)
+	}
+	conditions = append(conditions, dtConfig.ValueColumn+" IN ("+strings.Join(placeholders, ", ")+")")
+
+	columns := append([]string{
+		dtConfig.ValueColumn + " AS value",
+		labelExpression(dtConfig) + " AS label",
+	}, attributeColumns(dtConfig)...)
+
+	query = "SELECT " + strings.Join(columns, ", ") +
+		" FROM " + dtConfig.Table +
+		" WHERE " + strings.Join(conditions, " AND ")
+	return query, params, true
+}
+
+// UnionPerValue combines a query taking a value as its one placeholder into
+// a single query for several values. Each copy runs as a derived table, so
+// the query may have its own ORDER BY or LIMIT.
+func UnionPerValue(query string, values []string) (string, []interface{}) {
+	parts := make([]string, len(values))
+	params := make([]interface{}, len(values))
+	for i, value := range values {
+		parts[i] = "SELECT * FROM (" + query + ") lookup_" + strconv.Itoa(i+1)
+		params[i] = value
+	}
+	return strings.Join(parts, " UNION ALL "), params
+}
+
+// Keyset is the position after the last row of a page: the values of its
+// order columns, ending with the value column, nil for NULL
+type Keyset struct {
+	Keys []*string
+}
+
+// orderTerm is a column of a structured data type's page order
+type orderTerm struct {
+	column     string
+	descending bool
+}
+
+// pageOrder returns the columns pages of a structured data type are ordered
+// and keyed on: its orderBy columns, then the value column as the final
+// tiebreak unless listed, in the direction of the first column
+func pageOrder(dtConfig *config.DataTypeConfig) []orderTerm {
+	var terms []orderTerm
+	hasValue := false
+	for _, term := range strings.Split(dtConfig.OrderBy, ",") {
+		fields := strings.Fields(term)
+		if len(fields) == 0 {
+			continue
+		}
+		terms = append(terms, orderTerm{
+			column:     fields[0],
+			descending: len(fields) == 2 && strings.EqualFold(fields[1], "DESC"),
+		})
+		hasValue = hasValue || fields[0] == dtConfig.ValueColumn
+	}
+	if !hasValue {
+		descending := len(terms) > 0 && terms[0].descending
+		terms = append(terms, orderTerm{column: dtConfig.ValueColumn, descending: descending})
+	}
+	return terms
+}
+
+// BuildStructuredPageQuery generates the query for a page of a structured
+// data type's search results, ordered by its orderBy columns and then its
+// value column, with NULLs last. The order columns other than the value are
+// returned as sort_key_0, sort_key_1 and so on. after is the position of the
+// previous page's last row, or nil for the first page; it must fit the data
+// type (see KeysetFits). One row more than the limit is selected to tell
+// whether another page follows.
+func BuildStructuredPageQuery(dtConfig *config.DataTypeConfig, searchTerm string, limit int, after *Keyset) (string, []interface{}) {
+	terms := pageOrder(dtConfig)
+	conditions, params := structuredConditions(dtConfig, searchTerm)
+
+	if after != nil {
+		condition, keyParams := seekCondition(dtConfig, terms, after.Keys)
+		conditions = append(conditions, condition)
+		params = append(params, keyParams...)
+	}
+
+	columns := append([]string{
+		dtConfig.ValueColumn + " AS value",
+		labelExpression(dtConfig) + " AS label",
+	}, attributeColumns(dtConfig)...)
+	orderBy := make([]string, len(terms))
+	for i, term := range terms {
+		direction := "ASC"
+		if term.descending {
+			direction = "DESC"
+		}
+		orderBy[i] = term.column + " " + direction
+		if term.column != dtConfig.ValueColumn {
+			orderBy[i] += " NULLS LAST"
+			columns = append(columns, term.column+" AS sort_key_"+strconv.Itoa(i))
+		}
+	}
+
+	query := "SELECT " + strings.Join(columns, ", ") +
+		" FROM " + dtConfig.Table +
+		" WHERE " + strings.Join(conditions, " AND ") +
+		" ORDER BY " + strings.Join(orderBy, ", ") +
+		" LIMIT " + strconv.Itoa(limit+1)
+	return query, params
+}
+
+// seekCondition returns the condition selecting the rows after keys in the
+// page order: rows past the first column's key, or equal on it and past the
+// next, and so on. NULLs sort after every value.
+func seekCondition(dtConfig *config.DataTypeConfig, terms []orderTerm, keys []*string) (string, []interface{}) {
+	term, key := terms[0], keys[0]
+	operator := ">"
+	if term.descending {
+		operator = "<"
+	}
+
+	var alternatives []string
+	var params []interface{}
+	if key != nil {
+		alternatives = append(alternatives, term.column+" "+operator+" ?")
+		params = append(params, *key)
+		if term.column != dtConfig.ValueColumn {
+			alternatives = append(alternatives, term.column+" IS NULL")
+		}
+	}
+	if len(terms) > 1 {
+		equal := term.column + " IS NULL"
+		var equalParams []interface{}
+		if key != nil {
+			equal = term.column + " = ?"
+			equalParams = []interface{}{*key}
+		}
+		rest, restParams := seekCondition(dtConfig, terms[1:], keys[1:])
+		alternatives = append(alternatives, "("+equal+" AND "+rest+")")
+		params = append(append(params, equalParams...), restParams...)
+	}
+
+	if len(alternatives) == 0 {
+		return "1 = 0", nil
+	}
+	if len(alternatives) == 1 {
+		return alternatives[0], params
+	}
+	return "(" + strings.Join(alternatives, " OR ") + ")", params
+}
+
+// KeysetFromRow returns the position of a page query row
+func KeysetFromRow(dtConfig *config.DataTypeConfig, row map[string]string) Keyset {
+	terms := pageOrder(dtConfig)
+	keys := make([]*string, len(terms))
+	for i, term := range terms {
+		column := "sort_key_" + strconv.Itoa(i)
+		if term.column == dtConfig.ValueColumn {
+			column = "value"
+		}
+		if value, exists := row[column]; exists {
+			keys[i] = &value
+		}
+	}
+	return Keyset{Keys: keys}
+}
+
+// KeysetFits reports whether a position has a key for each order column of
+// the data type and a value
+func KeysetFits(dtConfig *config.DataTypeConfig, keyset Keyset) bool {
+	terms := pageOrder(dtConfig)
+	if len(keyset.Keys) != len(terms) {
+		return false
+	}
+	for i, term := range terms {
+		if term.column == dtConfig.ValueColumn && keyset.Keys[i] == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// attributeColumns returns the select list entries of a structured data
+// type's attributes, each aliased to the attribute name
+func attributeColumns(dtConfig *config.DataTypeConfig) []string {
+	columns := make([]string, len(dtConfig.Attributes))
+	for i, attribute := range dtConfig.Attributes {
+		columns[i] = attribute.ColumnName() + " AS " + attribute.Name
+	}
+	return columns
+}
+
+// structuredConditions returns the filter, context and search conditions of
+// a structured data type with their parameters
+func structuredConditions(dtConfig *config.DataTypeConfig, searchTerm string) ([]string, []interface{}) {
+	var params []interface{}
+	conditions := make([]string, 0, len(dtConfig.Filters)+len(dtConfig.Params)+1)
+	for _, filter := range dtConfig.Filters {
+		condition, values := filterCondition(filter)
+		conditions = append(conditions, condition)
+		params = append(params, values...)
+	}
+	contextWhere, contextParams := contextConditions(dtConfig)
+	conditions = append(conditions, contextWhere...)
+	params = append(params, contextParams...)
+	where, searchParams := searchConditions(dtConfig.SearchFields, searchTerm)
+	conditions = append(conditions, where)
+	params = append(params, searchParams...)
+	return conditions, params
+}
+
+// labelExpression returns the SQL for the label: the label template as a
+// concatenation, the label column, or the value column
+func labelExpression(dtConfig *config.DataTypeConfig) string {
+	if dtConfig.LabelTemplate == "" {
+		if dtConfig.LabelColumn != "" {
+			return dtConfig.LabelColumn
+		}
+		return dtConfig.ValueColumn
+	}
+
+	textType := textTypes[providerName(dtConfig)]
+	if textType == "" {
+		textType = "VARCHAR"
+	}
+
+	var parts []string
+	template := dtConfig.LabelTemplate
+	end := 0
+	for _, match := range templateField.FindAllStringSubmatchIndex(template, -1) {
+		if literal := template[end:match[0]]; literal != "" {
+			parts = append(parts, quoteLiteral(literal))
+		}
+		column := strings.TrimSpace(template[match[2]:match[3]])
+		parts = append(parts, "COALESCE(CAST("+column+" AS "+textType+"), '')")
+		end = match[1]
+	}
+	if literal := template[end:]; literal != "" {
+		parts = append(parts, quoteLiteral(literal))
+	}
+	return strings.Join(parts, " || ")
+}
+
+// filterCondition returns the SQL condition and parameters for a filter
+func filterCondition(filter config.FilterConfig) (string, []interface{}) {
+	operator := filterOperators[strings.ToLower(filter.Operator)]
+	switch operator {
+	case "", "=":
+		return filter.Column + " = ?", []interface{}{filter.Value}
+	case "IS NULL", "IS NOT NULL":
+		return filter.Column + " " + operator, nil
+	case "IN":
+		values, _ := filter.Value.([]interface{})
+		if len(values) == 0 {
+			return "1 = 0", nil
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+		return filter.Column + " IN (" + placeholders + ")", values
+	default:
+		return filter.Column + " " + operator + " ?", []interface{}{filter.Value}
+	}
+}
+
+// ValidateStructuredQuery checks a structured data type's table, columns and
+// filters, which are inserted into the generated SQL as written
+func ValidateStructuredQuery(dtConfig *config.DataTypeConfig) error {
+	if !identifier.MatchString(dtConfig.Table) {
+		return fmt.Errorf("table %q is not a table name", dtConfig.Table)
+	}
+	if dtConfig.ValueColumn == "" {
+		return fmt.Errorf("valueColumn is required with table")
+	}
+
+	columns := append([]string{dtConfig.ValueColumn}, dtConfig.SearchFields...)
+	if dtConfig.LabelColumn != "" {
+		columns = append(columns, dtConfig.LabelColumn)
+	}
+	for _, match := range templateField.FindAllStringSubmatch(dtConfig.LabelTemplate, -1) {
+		columns = append(columns, strings.TrimSpace(match[1]))
+	}
+	for _, filter := range dtConfig.Filters {
+		if _, ok := filterOperators[strings.ToLower(filter.Operator)]; !ok && filter.Operator != "" {
+			return fmt.Errorf("filter on %s: unknown operator %q", filter.Column, filter.Operator)
+		}
+		columns = append(columns, filter.Column)
+	}
+	for _, attribute := range dtConfig.Attributes {
+		columns = append(columns, attribute.ColumnName())
+	}
+	for _, column := range columns {
+		if !identifier.MatchString(column) {
+			return fmt.Errorf("%q is not a column name", column)
+		}
+	}
+
+	if dtConfig.OrderBy == "" {
+		return nil
+	}
+	for _, term := range strings.Split(dtConfig.OrderBy, ",") {
+		fields := strings.Fields(term)
+		if len(fields) == 0 || len(fields) > 2 || !identifier.MatchString(fields[0]) ||
+			(len(fields) == 2 && !strings.EqualFold(fields[1], "ASC") && !strings.EqualFold(fields[1], "DESC")) {
+			return fmt.Errorf("orderBy %q must list columns with optional ASC or DESC", dtConfig.OrderBy)
+		}
+	}
+	return nil
+}
+
+// providerName returns the provider a data type is served by
+func providerName(dtConfig *config.DataTypeConfig) string {
+	if dtConfig.Provider == "" {
+		return "snowflake"
+	}
+	return strings.ToLower(dtConfig.Provider)
+}
+
+// quoteLiteral returns s as an SQL string literal
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
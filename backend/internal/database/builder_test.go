@@ -0,0 +1,201 @@
+package database
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"snowflake-dropdown-api/internal/config"
+)
+
+func structuredConfig(provider string) *config.DataTypeConfig {
+	return &config.DataTypeConfig{
+		Provider:      provider,
+		Table:         "finance.cost_centers",
+		ValueColumn:   "code",
+		LabelTemplate: "{code} - {name}",
+		SearchFields:  []string{"code", "name"},
+		OrderBy:       "name DESC",
+		Filters: []config.FilterConfig{
+			{Column: "active", Value: true},
+			{Column: "region", Operator: "in", Value: []interface{}{"EU", "US"}},
+			{Column: "closed_at", Operator: "isnull"},
+		},
+	}
+}
+
+func TestBuildStructuredQuery(t *testing.T) {
+	config.AppConfig = &config.Config{}
+	config.AppConfig.SearchSettings.MaxResults = 25
+
+	query, params := BuildSearchQuery(structuredConfig("postgres"), "west")
+	wantQuery := "SELECT code AS value, COALESCE(CAST(code AS TEXT), '') || ' - ' || COALESCE(CAST(name AS TEXT), '') AS label " +
+		"FROM finance.cost_centers WHERE active = ? AND region IN (?, ?) AND closed_at IS NULL AND " +
+		"((UPPER(code) LIKE ? ESCAPE '!' OR UPPER(name) LIKE ? ESCAPE '!')) ORDER BY name DESC LIMIT 25"
+	wantParams := []interface{}{true, "EU", "US", "%WEST%", "%WEST%"}
+	if query != wantQuery {
+		t.Errorf("query = %s\nwant    %s", query, wantQuery)
+	}
+	if !reflect.DeepEqual(params, wantParams) {
+		t.Errorf("params = %v, want %v", params, wantParams)
+	}
+
+	snowflake, _ := BuildSearchQuery(structuredConfig(""), "west")
+	if want := "COALESCE(CAST(code AS VARCHAR), '')"; !strings.Contains(snowflake, want) {
+		t.Errorf("snowflake query = %s, want it to cast with %s", snowflake, want)
+	}
+}
+
+func TestBuildStructuredDatasetQuery(t *testing.T) {
+	dt := &config.DataTypeConfig{
+		Table:        "cost_centers",
+		ValueColumn:  "code",
+		LabelColumn:  "name",
+		SearchFields: []string{"code", "name"},
+	}
+	query, params := BuildStructuredDatasetQuery(dt)
+	want := "SELECT code AS value, name AS label, code, name FROM cost_centers WHERE (1 = 1) ORDER BY code"
+	if query != want || len(params) != 0 {
+		t.Errorf("query = %s, params = %v\nwant    %s", query, params, want)
+	}
+}
+
+func TestValidateStructuredQuery(t *testing.T) {
+	valid := structuredConfig("sqlite")
+	if err := ValidateSearchQuery(valid); err != nil {
+		t.Errorf("ValidateSearchQuery(valid) error = %v", err)
+	}
+
+	tests := map[string]func(dt *config.DataTypeConfig){
+		"table":       func(dt *config.DataTypeConfig) { dt.Table = "cost_centers; DROP TABLE x" },
+		"valueColumn": func(dt *config.DataTypeConfig) { dt.ValueColumn = "" },
+		"template":    func(dt *config.DataTypeConfig) { dt.LabelTemplate = "{name || password}" },
+		"operator":    func(dt *config.DataTypeConfig) { dt.Filters[0].Operator = "between" },
+		"orderBy":     func(dt *config.DataTypeConfig) { dt.OrderBy = "name DESC, (SELECT 1)" },
+	}
+	for name, mutate := range tests {
+		dt := structuredConfig("sqlite")
+		mutate(dt)
+		if err := ValidateSearchQuery(dt); err == nil {
+			t.Errorf("%s: ValidateSearchQuery() error = nil", name)
+		}
+	}
+
+	if err := ValidateSearchQuery(&config.DataTypeConfig{}); err == nil {
+		t.Error("ValidateSearchQuery() error = nil without a query or table")
+	}
+}
+
+func TestBuildStructuredPageQuery(t *testing.T) {
+	dt := &config.DataTypeConfig{
+		Table:        "cost_centers",
+		ValueColumn:  "code",
+		SearchFields: []string{"name"},
+		OrderBy:      "name DESC, code",
+	}
+
+	sales, cc003, eu := "Sales", "CC003", "EU"
+	query, params := BuildStructuredPageQuery(dt, "", 20, &Keyset{Keys: []*string{&sales, &cc003}})
+	want := "SELECT code AS value, code AS label, name AS sort_key_0 FROM cost_centers " +
+		"WHERE (1 = 1) AND (name < ? OR name IS NULL OR (name = ? AND code > ?)) ORDER BY name DESC NULLS LAST, code ASC LIMIT 21"
+	if query != want {
+		t.Errorf("query = %s\nwant    %s", query, want)
+	}
+	if wantParams := []interface{}{"Sales", "Sales", "CC003"}; !reflect.DeepEqual(params, wantParams) {
+		t.Errorf("params = %v, want %v", params, wantParams)
+	}
+
+	// Secondary columns and NULL keys are part of the position
+	dt.OrderBy = "region, name DESC"
+	query, params = BuildStructuredPageQuery(dt, "", 5, &Keyset{Keys: []*string{&eu, nil, &cc003}})
+	want = "SELECT code AS value, code AS label, region AS sort_key_0, name AS sort_key_1 FROM cost_centers " +
+		"WHERE (1 = 1) AND (region > ? OR region IS NULL OR (region = ? AND (name IS NULL AND code > ?))) " +
+		"ORDER BY region ASC NULLS LAST, name DESC NULLS LAST, code ASC LIMIT 6"
+	if query != want {
+		t.Errorf("query = %s\nwant    %s", query, want)
+	}
+	if wantParams := []interface{}{"EU", "EU", "CC003"}; !reflect.DeepEqual(params, wantParams) {
+		t.Errorf("params = %v, want %v", params, wantParams)
+	}
+	row := map[string]string{"value": "CC004", "sort_key_0": "EU"}
+	if keyset := KeysetFromRow(dt, row); keyset.Keys[1] != nil || *keyset.Keys[2] != "CC004" || !KeysetFits(dt, keyset) {
+		t.Errorf("KeysetFromRow() = %v", keyset)
+	}
+
+	dt.OrderBy = ""
+	query, params = BuildStructuredPageQuery(dt, "", 5, &Keyset{Keys: []*string{&cc003}})
+	want = "SELECT code AS value, code AS label FROM cost_centers " +
+		"WHERE (1 = 1) AND code > ? ORDER BY code ASC LIMIT 6"
+	if query != want || len(params) != 1 {
+		t.Errorf("query = %s, params = %v\nwant    %s", query, params, want)
+	}
+	if KeysetFits(dt, Keyset{Keys: []*string{&eu, &cc003}}) {
+		t.Error("KeysetFits() accepted a key per column of another order")
+	}
+}
+
+func TestStructuredAttributes(t *testing.T) {
+	dt := &config.DataTypeConfig{
+		Table:       "cost_centers",
+		ValueColumn: "code",
+		LabelColumn: "name",
+		Attributes:  []config.AttributeConfig{{Name: "owner"}, {Name: "validTo", Column: "valid_to"}},
+	}
+	wantColumns := "SELECT code AS value, name AS label, owner AS owner, valid_to AS validTo"
+
+	query, _ := BuildStructuredDatasetQuery(dt)
+	lookup, _, _ := BuildLookupQuery(dt, "CC001")
+	page, _ := BuildStructuredPageQuery(dt, "", 10, nil)
+	for _, query := range []string{query, lookup, page} {
+		if !strings.HasPrefix(query, wantColumns) {
+			t.Errorf("query = %s\nwant it to start with %s", query, wantColumns)
+		}
+	}
+
+	dt.Attributes[1].Column = "valid_to; DROP TABLE x"
+	if err := ValidateSearchQuery(dt); err == nil {
+		t.Error("ValidateSearchQuery() error = nil for an attribute column that is not a column name")
+	}
+}
+
+func TestBuildBatchLookupQuery(t *testing.T) {
+	dt := &config.DataTypeConfig{Table: "cost_centers", ValueColumn: "code", LabelColumn: "name"}
+	query, params, ok := BuildBatchLookupQuery(dt, []string{"CC001", "CC002"})
+	want := "SELECT code AS value, name AS label FROM cost_centers WHERE (1 = 1) AND code IN (?, ?)"
+	if !ok || query != want || len(params) != 2 {
+		t.Errorf("query = %s, params = %v\nwant    %s", query, params, want)
+	}
+
+	dt = &config.DataTypeConfig{Query: "SELECT 1", LookupQuery: "SELECT code AS value, name AS label FROM cc WHERE code = ? LIMIT 1"}
+	query, params, ok = BuildBatchLookupQuery(dt, []string{"CC001", "CC002"})
+	want = "SELECT * FROM (SELECT code AS value, name AS label FROM cc WHERE code = ? LIMIT 1) lookup_1 UNION ALL " +
+		"SELECT * FROM (SELECT code AS value, name AS label FROM cc WHERE code = ? LIMIT 1) lookup_2"
+	if !ok || query != want || !reflect.DeepEqual(params, []interface{}{"CC001", "CC002"}) {
+		t.Errorf("query = %s, params = %v\nwant    %s", query, params, want)
+	}
+
+	if _, _, ok := BuildBatchLookupQuery(&config.DataTypeConfig{Query: "SELECT 1"}, []string{"CC001"}); ok {
+		t.Error("BuildBatchLookupQuery() ok without a lookup query or table")
+	}
+}
+
+func TestBuildLookupQuery(t *testing.T) {
+	dt := structuredConfig("sqlite")
+	query, params, ok := BuildLookupQuery(dt, "CC001")
+	want := "SELECT code AS value, COALESCE(CAST(code AS TEXT), '') || ' - ' || COALESCE(CAST(name AS TEXT), '') AS label " +
+		"FROM finance.cost_centers WHERE active = ? AND region IN (?, ?) AND closed_at IS NULL AND (1 = 1) AND code = ?"
+	if !ok || query != want {
+		t.Errorf("query = %s\nwant    %s", query, want)
+	}
+	if wantParams := []interface{}{true, "EU", "US", "CC001"}; !reflect.DeepEqual(params, wantParams) {
+		t.Errorf("params = %v, want %v", params, wantParams)
+	}
+
+	dt = &config.DataTypeConfig{Query: "SELECT 1", LookupQuery: "SELECT code AS value, name AS label FROM cc WHERE code = ?"}
+	if query, params, ok := BuildLookupQuery(dt, "CC001"); !ok || query != dt.LookupQuery || len(params) != 1 {
+		t.Errorf("BuildLookupQuery() = %s, %v, %v; want the lookup query", query, params, ok)
+	}
+	if _, _, ok := BuildLookupQuery(&config.DataTypeConfig{Query: "SELECT 1"}, "CC001"); ok {
+		t.Error("BuildLookupQuery() ok for a data type without a lookup query")
+	}
+}
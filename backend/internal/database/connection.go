@@ -1,123 +1,80 @@
 package database
 
 import (
-	"context"
 	"database/sql"
 	"fmt"
-	"log"
-	"net/url"
-	"os"
-	"strings"
+	"sync"
 	"time"
 
-	_ "github.com/snowflakedb/gosnowflake"
+	"snowflake-dropdown-api/internal/config"
 )
 
-var DB *sql.DB
-
-// InitializeDatabase establishes the Snowflake connection with retry logic
-func InitializeDatabase() error {
-	if os.Getenv("TEST_MODE") == "true" {
-		log.Println("TEST_MODE enabled - skipping database connection")
-		return nil
+// Open creates a connection pool and applies the configured pool settings
+func Open(driver, dsn string, conn *config.ConnectionConfig) (*sql.DB, error) {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create database connection: %v", err)
 	}
 
-	log.Println("Initializing Snowflake connection...")
-	log.Println("Note: If using SSO, your browser will open for authentication")
-
-	maxRetries := 3
-	retryDelay := 5 * time.Second
-
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		var err error
-		DB, err = sql.Open("snowflake", getConnectionString())
-		if err != nil {
-			return fmt.Errorf("failed to create database connection: %v", err)
+	if conn != nil {
+		if conn.MaxOpenConns > 0 {
+			db.SetMaxOpenConns(conn.MaxOpenConns)
 		}
-
-		// Test the connection
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		err = DB.PingContext(ctx)
-		cancel()
-
-		if err == nil {
-			log.Println("Successfully connected to Snowflake")
-			return nil
+		if conn.MaxIdleConns > 0 {
+			db.SetMaxIdleConns(conn.MaxIdleConns)
 		}
-
-		// Check if it's an invalid token error (390195)
-		if strings.Contains(err.Error(), "390195") || strings.Contains(err.Error(), "invalid") && strings.Contains(err.Error(), "token") {
-			log.Printf("Authentication failed (attempt %d/%d): Invalid ID Token detected", attempt, maxRetries)
-
-			if attempt < maxRetries {
-				log.Printf("Retrying authentication in %v...", retryDelay)
-
-				// Close the current connection before retry
-				if DB != nil {
-					DB.Close()
-					DB = nil
-				}
-
-				time.Sleep(retryDelay)
-				continue
-			}
+		if conn.ConnMaxLifetimeMinutes > 0 {
+			db.SetConnMaxLifetime(time.Duration(conn.ConnMaxLifetimeMinutes) * time.Minute)
 		}
-
-		// For other errors, don't retry
-		return fmt.Errorf("failed to connect to Snowflake: %v", err)
 	}
 
-	return fmt.Errorf("failed to connect to Snowflake after %d attempts: invalid ID token", maxRetries)
+	return db, nil
 }
 
-// getConnectionString builds the Snowflake connection string
-func getConnectionString() string {
-	account := os.Getenv("SNOWFLAKE_ACCOUNT")
-
-	// Check if using SSO authentication
-	if os.Getenv("SNOWFLAKE_AUTH_TYPE") == "externalbrowser" {
-		// For SSO/External Browser auth, no password needed
-		dsn := fmt.Sprintf("%s@%s/%s/%s?warehouse=%s&authenticator=externalbrowser",
-			os.Getenv("SNOWFLAKE_USER"),
-			account,
-			os.Getenv("SNOWFLAKE_DATABASE"),
-			os.Getenv("SNOWFLAKE_SCHEMA"),
-			os.Getenv("SNOWFLAKE_WAREHOUSE"),
-		)
-
-		if role := os.Getenv("SNOWFLAKE_ROLE"); role != "" {
-			dsn += "&role=" + role
-		}
+// Pools caches connection pools by data source name so data types sharing
+// connection settings share a pool
+type Pools struct {
+	mu     sync.Mutex
+	driver string
+	pools  map[string]*sql.DB
+}
 
-		log.Printf("Connecting to Snowflake using SSO (external browser) for account: %s", account)
-		return dsn
+// NewPools creates an empty pool cache for the given driver
+func NewPools(driver string) *Pools {
+	return &Pools{
+		driver: driver,
+		pools:  make(map[string]*sql.DB),
 	}
+}
 
-	// Standard username/password authentication
-	password := url.QueryEscape(os.Getenv("SNOWFLAKE_PASSWORD"))
-
-	dsn := fmt.Sprintf("%s:%s@%s/%s/%s?warehouse=%s",
-		os.Getenv("SNOWFLAKE_USER"),
-		password,
-		account,
-		os.Getenv("SNOWFLAKE_DATABASE"),
-		os.Getenv("SNOWFLAKE_SCHEMA"),
-		os.Getenv("SNOWFLAKE_WAREHOUSE"),
-	)
+// Get returns the pool for a data source name, opening it on first use
+func (p *Pools) Get(dsn string, conn *config.ConnectionConfig) (*sql.DB, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
-	if role := os.Getenv("SNOWFLAKE_ROLE"); role != "" {
-		dsn += "&role=" + role
+	if db, exists := p.pools[dsn]; exists {
+		return db, nil
 	}
 
-	log.Printf("Connecting to Snowflake account: %s", account)
-
-	return dsn
+	db, err := Open(p.driver, dsn, conn)
+	if err != nil {
+		return nil, err
+	}
+	p.pools[dsn] = db
+	return db, nil
 }
 
-// Close closes the database connection
-func Close() error {
-	if DB != nil {
-		return DB.Close()
+// Close closes every pool
+func (p *Pools) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var firstErr error
+	for dsn, db := range p.pools {
+		if err := db.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(p.pools, dsn)
 	}
-	return nil
-}
\ No newline at end of file
+	return firstErr
+}
@@ -0,0 +1,96 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"snowflake-dropdown-api/internal/config"
+)
+
+// BindContext replaces the {name} references to a data type's context
+// parameters in a query with placeholders, inserting the parameter values
+// among params in placeholder order. Parameters without a value bind an
+// empty string. References inside quotes are left alone.
+func BindContext(dtConfig *config.DataTypeConfig, query string, params []interface{}) (string, []interface{}) {
+	if len(dtConfig.Params) == 0 {
+		return query, params
+	}
+
+	var b strings.Builder
+	b.Grow(len(query))
+	bound := make([]interface{}, 0, len(params)+len(dtConfig.Params))
+	next := 0 // index of the parameter for the next placeholder
+
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '?':
+			if next < len(params) {
+				bound = append(bound, params[next])
+				next++
+			}
+		case c == '{':
+			if name, ok := contextReference(dtConfig, query[i:]); ok {
+				b.WriteByte('?')
+				bound = append(bound, dtConfig.ContextValues[name])
+				i += len(name) + 1
+				continue
+			}
+		}
+		b.WriteByte(c)
+	}
+
+	// Parameters beyond the placeholders, such as an unused limit, stay last
+	bound = append(bound, params[next:]...)
+	return b.String(), bound
+}
+
+// contextReference returns the name of the context parameter referenced at
+// the start of s
+func contextReference(dtConfig *config.DataTypeConfig, s string) (string, bool) {
+	for _, param := range dtConfig.Params {
+		if strings.HasPrefix(s, "{"+param.Name+"}") {
+			return param.Name, true
+		}
+	}
+	return "", false
+}
+
+// contextConditions returns the conditions of a structured data type's
+// context parameters that have a value
+func contextConditions(dtConfig *config.DataTypeConfig) ([]string, []interface{}) {
+	var conditions []string
+	var params []interface{}
+	for _, param := range dtConfig.Params {
+		if value := dtConfig.ContextValues[param.Name]; value != "" {
+			conditions = append(conditions, param.Column+" = ?")
+			params = append(params, value)
+		}
+	}
+	return conditions, params
+}
+
+// ValidateContextParams checks that a data type's context parameters can be
+// bound: structured data types filter each on a column, hand-written queries
+// must reference each as {name}
+func ValidateContextParams(dtConfig *config.DataTypeConfig) error {
+	for _, param := range dtConfig.Params {
+		if Structured(dtConfig) {
+			if !identifier.MatchString(param.Column) {
+				return fmt.Errorf("param %s: column %q is not a column name", param.Name, param.Column)
+			}
+			continue
+		}
+		if !strings.Contains(dtConfig.Query, "{"+param.Name+"}") {
+			return fmt.Errorf("param %s is not referenced as {%s} in the query", param.Name, param.Name)
+		}
+	}
+	return nil
+}
@@ -1,12 +1,42 @@
 package database
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
 	"strings"
+	"unicode"
+
 	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/models"
 )
 
-// BuildSearchQuery builds a parameterized query for searching
+// SearchPlaceholder marks where BuildSearchQuery inserts the generated
+// search conditions in a data type query
+const SearchPlaceholder = "{search}"
+
+// maxSearchTokens bounds the number of generated per-token conditions
+const maxSearchTokens = 8
+
+// identifier matches the column references allowed in generated conditions
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*(\.[A-Za-z_][A-Za-z0-9_$]*)*$`)
+
+// BuildSearchQuery builds a parameterized query for searching. Structured
+// data types get a generated query and queries containing SearchPlaceholder
+// generated conditions; others keep their hand-written placeholders for the
+// term and one LIKE pattern per search field. Context parameters are bound
+// as further placeholders.
 func BuildSearchQuery(dtConfig *config.DataTypeConfig, searchTerm string) (string, []interface{}) {
+	if Structured(dtConfig) {
+		return BuildStructuredQuery(dtConfig, searchTerm)
+	}
+	if strings.Contains(dtConfig.Query, SearchPlaceholder) {
+		return buildTokenQuery(dtConfig, searchTerm)
+	}
+
 	params := []interface{}{searchTerm}
 
 	// Add parameters for each search field
@@ -15,11 +45,342 @@ func BuildSearchQuery(dtConfig *config.DataTypeConfig, searchTerm string) (strin
 	}
 
 	// Add the limit parameter from global config
-	maxResults := 100 // default
-	if appConfig := config.AppConfig; appConfig != nil {
-		maxResults = appConfig.SearchSettings.MaxResults
+	params = append(params, config.GetMaxResults())
+
+	return BindContext(dtConfig, dtConfig.Query, params)
+}
+
+// buildTokenQuery replaces SearchPlaceholder with the generated search conditions
+func buildTokenQuery(dtConfig *config.DataTypeConfig, searchTerm string) (string, []interface{}) {
+	where, params := searchConditions(dtConfig.SearchFields, searchTerm)
+
+	// A trailing LIMIT ? in the query takes the maximum result count
+	params = append(params, config.GetMaxResults())
+
+	return BindContext(dtConfig, strings.Replace(dtConfig.Query, SearchPlaceholder, where, 1), params)
+}
+
+// searchConditions builds one condition per search token, combined with AND.
+// A token matches when any of the fields contains it.
+func searchConditions(fields []string, searchTerm string) (string, []interface{}) {
+	tokens := ParseSearchTerms(searchTerm)
+	if len(tokens) > maxSearchTokens {
+		tokens = tokens[:maxSearchTokens]
+	}
+	if len(tokens) == 0 || len(fields) == 0 {
+		return "(1 = 1)", nil
+	}
+
+	var params []interface{}
+	conditions := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		pattern := "%" + escapeLike(strings.ToUpper(token)) + "%"
+		matches := make([]string, len(fields))
+		for i, field := range fields {
+			matches[i] = "UPPER(" + field + ") LIKE ? ESCAPE '!'"
+			params = append(params, pattern)
+		}
+		conditions = append(conditions, "("+strings.Join(matches, " OR ")+")")
+	}
+
+	return "(" + strings.Join(conditions, " AND ") + ")", params
+}
+
+// ParseSearchTerms splits a search string into tokens on whitespace, keeping
+// double-quoted phrases together as one token
+func ParseSearchTerms(searchTerm string) []string {
+	var tokens []string
+	var current strings.Builder
+	quoted := false
+
+	flush := func() {
+		if token := strings.TrimSpace(current.String()); token != "" {
+			tokens = append(tokens, token)
+		}
+		current.Reset()
+	}
+
+	for _, r := range searchTerm {
+		switch {
+		case r == '"':
+			flush()
+			quoted = !quoted
+		case !quoted && unicode.IsSpace(r):
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+
+	return tokens
+}
+
+// escapeLike escapes LIKE wildcards using ! as the escape character
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
+
+// ValidateSearchQuery checks that a data type has a query, that generated
+// SQL only references plain column names and that its context parameters
+// can be bound
+func ValidateSearchQuery(dtConfig *config.DataTypeConfig) error {
+	if err := ValidateContextParams(dtConfig); err != nil {
+		return err
+	}
+	if Structured(dtConfig) {
+		return ValidateStructuredQuery(dtConfig)
+	}
+	if dtConfig.Query == "" {
+		return fmt.Errorf("query or table is required")
+	}
+	if !strings.Contains(dtConfig.Query, SearchPlaceholder) {
+		return nil
+	}
+	if len(dtConfig.SearchFields) == 0 {
+		return fmt.Errorf("searchFields are required for queries using %s", SearchPlaceholder)
+	}
+	for _, field := range dtConfig.SearchFields {
+		if !identifier.MatchString(field) {
+			return fmt.Errorf("search field %q is not a column name", field)
+		}
+	}
+	return nil
+}
+
+// ValidateDatasetQuery checks that snapshot mode has a full dataset query:
+// hand-written search queries only return the first results, so they need
+// snapshot.query, while structured data types generate one
+func ValidateDatasetQuery(dtConfig *config.DataTypeConfig) error {
+	if dtConfig.Snapshot == nil || !dtConfig.Snapshot.Enabled || Structured(dtConfig) {
+		return nil
+	}
+	if dtConfig.Snapshot.Query == "" {
+		return fmt.Errorf("snapshot.query is required in snapshot mode, the search query only returns the first results")
+	}
+	return nil
+}
+
+// ValidatePlaceholders checks that a data type's queries have a placeholder
+// for each parameter bound by BuildSearchQuery, the limit being optional,
+// that its lookup and status queries take the value and its snapshot and
+// fuzzy candidate queries take none
+func ValidatePlaceholders(dtConfig *config.DataTypeConfig) error {
+	if dtConfig.Snapshot != nil && CountPlaceholders(dtConfig.Snapshot.Query) > 0 {
+		return fmt.Errorf("snapshot.query must not contain placeholders")
+	}
+	if dtConfig.Fuzzy != nil && CountPlaceholders(dtConfig.Fuzzy.CandidateQuery) > 0 {
+		return fmt.Errorf("fuzzy.candidateQuery must not contain placeholders")
+	}
+	if count := CountPlaceholders(dtConfig.LookupQuery); dtConfig.LookupQuery != "" && count != 1 {
+		return fmt.Errorf("lookupQuery has %d placeholders, want 1 for the value", count)
+	}
+	if dtConfig.Status != nil {
+		if count := CountPlaceholders(dtConfig.Status.Query); count != 1 {
+			return fmt.Errorf("status.query has %d placeholders, want 1 for the value", count)
+		}
+	}
+	if Structured(dtConfig) {
+		return nil
 	}
-	params = append(params, maxResults)
 
-	return dtConfig.Query, params
-}
\ No newline at end of file
+	if strings.Contains(dtConfig.Query, SearchPlaceholder) {
+		count := CountPlaceholders(strings.Replace(dtConfig.Query, SearchPlaceholder, "", 1))
+		if count > 1 {
+			return fmt.Errorf("query has %d placeholders besides %s, want at most one for the limit", count, SearchPlaceholder)
+		}
+		return nil
+	}
+
+	count := CountPlaceholders(dtConfig.Query)
+	want := 1 + len(dtConfig.SearchFields)
+	if count != want && count != want+1 {
+		return fmt.Errorf("query has %d placeholders, want %d (the search term and one pattern for each of the %d search fields) or %d with a limit placeholder",
+			count, want, len(dtConfig.SearchFields), want+1)
+	}
+	return nil
+}
+
+// Rebind rewrites ? placeholders as numbered $n placeholders, skipping
+// quoted strings and identifiers. It returns the query and placeholder count.
+func Rebind(query string) (string, int) {
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+
+	count := 0
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			// A doubled quote closes and immediately reopens the literal
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '?':
+			count++
+			b.WriteString("$" + strconv.Itoa(count))
+			continue
+		}
+		b.WriteByte(c)
+	}
+
+	return b.String(), count
+}
+
+// CountPlaceholders returns the number of ? placeholders in a query
+func CountPlaceholders(query string) int {
+	_, count := Rebind(query)
+	return count
+}
+
+// TrimParams drops parameters the query has no placeholders for, such as
+// the trailing limit appended by BuildSearchQuery
+func TrimParams(params []interface{}, count int) []interface{} {
+	if len(params) > count {
+		return params[:count]
+	}
+	return params
+}
+
+// QueryItems executes a query and scans each row into a dropdown item, taking
+// the value and label from the first two columns and the attributes from the
+// columns named after them. Other columns are ignored.
+func QueryItems(ctx context.Context, db *sql.DB, attributes []config.AttributeConfig, query string, params ...interface{}) ([]models.DropdownItem, error) {
+	rows, err := db.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) < 2 {
+		return nil, fmt.Errorf("query returns %d column(s), want value and label", len(columns))
+	}
+	for i := range columns {
+		columns[i] = strings.ToLower(columns[i])
+	}
+
+	var items []models.DropdownItem
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			log.Printf("Error scanning row: %v", err)
+			continue
+		}
+		if !values[0].Valid || !values[1].Valid {
+			log.Printf("Skipping row with NULL value or label")
+			continue
+		}
+
+		item := models.DropdownItem{Value: values[0].String, Label: values[1].String}
+		if len(attributes) > 0 {
+			row := make(map[string]string, len(columns))
+			for i, column := range columns {
+				row[column] = values[i].String
+			}
+			item.Attributes = RowAttributes(attributes, row)
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// RowAttributes returns the attributes with a value in a row keyed by
+// lower-cased column name, or nil if there are none
+func RowAttributes(attributes []config.AttributeConfig, row map[string]string) map[string]string {
+	var result map[string]string
+	for _, attribute := range attributes {
+		value := row[strings.ToLower(attribute.Name)]
+		if value == "" {
+			continue
+		}
+		if result == nil {
+			result = make(map[string]string, len(attributes))
+		}
+		result[attribute.Name] = value
+	}
+	return result
+}
+
+// QueryRows executes a query and returns every row as a map of lower-cased
+// column names to string values. NULL columns are left out of the map.
+func QueryRows(ctx context.Context, db *sql.DB, query string, params ...interface{}) ([]map[string]string, error) {
+	rows, err := db.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	for i := range columns {
+		columns[i] = strings.ToLower(columns[i])
+	}
+
+	var result []map[string]string
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			log.Printf("Error scanning row: %v", err)
+			continue
+		}
+
+		row := make(map[string]string, len(columns))
+		for i, column := range columns {
+			if values[i].Valid {
+				row[column] = values[i].String
+			}
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+// QueryColumns returns the lower-cased names of the columns a query returns.
+// The query is wrapped in a condition that is never true, so the database
+// checks it without reading any rows.
+func QueryColumns(ctx context.Context, db *sql.DB, query string, params ...interface{}) ([]string, error) {
+	query = strings.TrimRight(strings.TrimSpace(query), ";")
+	rows, err := db.QueryContext(ctx, "SELECT * FROM ("+query+") probe WHERE 1 = 0", params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	for i := range columns {
+		columns[i] = strings.ToLower(columns[i])
+	}
+	return columns, nil
+}
+
+// FindItem returns the item whose value matches exactly, or nil
+func FindItem(items []models.DropdownItem, value string) *models.DropdownItem {
+	for i := range items {
+		if items[i].Value == value {
+			return &items[i]
+		}
+	}
+	return nil
+}
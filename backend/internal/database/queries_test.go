@@ -0,0 +1,152 @@
+package database
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"snowflake-dropdown-api/internal/config"
+)
+
+func TestParseSearchTerms(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"", nil},
+		{"  marketing   west ", []string{"marketing", "west"}},
+		{`"west region" marketing`, []string{"west region", "marketing"}},
+		{`cc"north east"`, []string{"cc", "north east"}},
+		{`"unterminated phrase`, []string{"unterminated phrase"}},
+	}
+	for _, tt := range tests {
+		if got := ParseSearchTerms(tt.input); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseSearchTerms(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestBuildSearchQueryGeneratesTokenConditions(t *testing.T) {
+	config.AppConfig = &config.Config{}
+	config.AppConfig.SearchSettings.MaxResults = 50
+	dt := &config.DataTypeConfig{
+		Query:        "SELECT id AS value, name AS label FROM cc WHERE {search} ORDER BY id LIMIT ?",
+		SearchFields: []string{"id", "name"},
+	}
+
+	query, params := BuildSearchQuery(dt, `west "50%_off"`)
+	wantQuery := "SELECT id AS value, name AS label FROM cc WHERE (" +
+		"(UPPER(id) LIKE ? ESCAPE '!' OR UPPER(name) LIKE ? ESCAPE '!') AND " +
+		"(UPPER(id) LIKE ? ESCAPE '!' OR UPPER(name) LIKE ? ESCAPE '!')) ORDER BY id LIMIT ?"
+	wantParams := []interface{}{"%WEST%", "%WEST%", "%50!%!_OFF%", "%50!%!_OFF%", 50}
+	if query != wantQuery {
+		t.Errorf("query = %s\nwant    %s", query, wantQuery)
+	}
+	if !reflect.DeepEqual(params, wantParams) {
+		t.Errorf("params = %v, want %v", params, wantParams)
+	}
+
+	query, params = BuildSearchQuery(dt, "  ")
+	if query != "SELECT id AS value, name AS label FROM cc WHERE (1 = 1) ORDER BY id LIMIT ?" || len(params) != 1 {
+		t.Errorf("empty term query = %s, params = %v", query, params)
+	}
+}
+
+func TestValidateSearchQuery(t *testing.T) {
+	tests := []struct {
+		dt      config.DataTypeConfig
+		wantErr bool
+	}{
+		{config.DataTypeConfig{Query: "SELECT 1 WHERE ? = ''", SearchFields: []string{"a b"}}, false},
+		{config.DataTypeConfig{Query: "SELECT 1 WHERE {search}", SearchFields: []string{"code", "t.name"}}, false},
+		{config.DataTypeConfig{Query: "SELECT 1 WHERE {search}"}, true},
+		{config.DataTypeConfig{Query: "SELECT 1 WHERE {search}", SearchFields: []string{"name) OR (1=1"}}, true},
+	}
+	for _, tt := range tests {
+		if err := ValidateSearchQuery(&tt.dt); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateSearchQuery(%+v) error = %v, wantErr %v", tt.dt, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidatePlaceholders(t *testing.T) {
+	fields := []string{"code", "name"}
+	tests := []struct {
+		dt      config.DataTypeConfig
+		wantErr string
+	}{
+		{config.DataTypeConfig{Query: "SELECT 1 WHERE ? = '' OR code LIKE ? OR name LIKE ?", SearchFields: fields}, ""},
+		{config.DataTypeConfig{Query: "SELECT 1 WHERE ? = '' OR code LIKE ? OR name LIKE ? LIMIT ?", SearchFields: fields}, ""},
+		{config.DataTypeConfig{Query: "SELECT 1 WHERE ? = '' OR code LIKE ? OR name = '?'", SearchFields: fields}, "query has 2 placeholders, want 3"},
+		{config.DataTypeConfig{Query: "SELECT 1 WHERE {search} LIMIT ?", SearchFields: fields}, ""},
+		{config.DataTypeConfig{Query: "SELECT 1 WHERE {search} AND ? = ? LIMIT ?", SearchFields: fields}, "3 placeholders besides {search}"},
+		{config.DataTypeConfig{Table: "cc", ValueColumn: "code"}, ""},
+		{config.DataTypeConfig{Table: "cc", ValueColumn: "code", Snapshot: &config.SnapshotConfig{Query: "SELECT * FROM cc WHERE id > ?"}}, "snapshot.query"},
+	}
+	for _, tt := range tests {
+		err := ValidatePlaceholders(&tt.dt)
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("ValidatePlaceholders(%q) error = %v", tt.dt.Query, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("ValidatePlaceholders(%q) error = %v, want %q", tt.dt.Query, err, tt.wantErr)
+		}
+	}
+}
+
+func TestBindContext(t *testing.T) {
+	config.AppConfig = &config.Config{}
+	config.AppConfig.SearchSettings.MaxResults = 50
+	dt := &config.DataTypeConfig{
+		Query:        "SELECT code AS value, name AS label FROM wbs WHERE ({costCenter} = '' OR cost_center = {costCenter}) AND note <> '{costCenter}' AND (? = '' OR UPPER(name) LIKE ?) ORDER BY code",
+		SearchFields: []string{"name"},
+		Params:       []config.ParamConfig{{Name: "costCenter"}},
+	}
+
+	query, params := BuildSearchQuery(dt.WithContext(map[string]string{"costCenter": "CC001"}), "alpha")
+	wantQuery := "SELECT code AS value, name AS label FROM wbs WHERE (? = '' OR cost_center = ?) AND note <> '{costCenter}' AND (? = '' OR UPPER(name) LIKE ?) ORDER BY code"
+	if query != wantQuery {
+		t.Errorf("query = %q\nwant %q", query, wantQuery)
+	}
+	wantParams := []interface{}{"CC001", "CC001", "alpha", "%ALPHA%", 50}
+	if !reflect.DeepEqual(params, wantParams) {
+		t.Errorf("params = %v, want %v", params, wantParams)
+	}
+
+	// Without a value the parameter binds an empty string
+	if _, params = BuildSearchQuery(dt, ""); !reflect.DeepEqual(params[:2], []interface{}{"", ""}) {
+		t.Errorf("unbound params = %v", params)
+	}
+}
+
+func TestValidateContextParams(t *testing.T) {
+	tests := []struct {
+		dt      config.DataTypeConfig
+		wantErr string
+	}{
+		{config.DataTypeConfig{Query: "SELECT 1 WHERE x = {cc}", Params: []config.ParamConfig{{Name: "cc"}}}, ""},
+		{config.DataTypeConfig{Query: "SELECT 1 WHERE x = :cc", Params: []config.ParamConfig{{Name: "cc"}}}, "not referenced as {cc}"},
+		{config.DataTypeConfig{Table: "wbs", Params: []config.ParamConfig{{Name: "cc", Column: "cost_center"}}}, ""},
+		{config.DataTypeConfig{Table: "wbs", Params: []config.ParamConfig{{Name: "cc"}}}, "is not a column name"},
+	}
+	for _, tt := range tests {
+		err := ValidateContextParams(&tt.dt)
+		if (tt.wantErr == "" && err != nil) || (tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr))) {
+			t.Errorf("ValidateContextParams(%+v) = %v, want %q", tt.dt.Params, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRebindTranslatesPlaceholders(t *testing.T) {
+	query := "SELECT id, name FROM t WHERE (? = '' OR name LIKE ? OR note = 'why?' OR \"odd?col\" LIKE ?)"
+
+	got, count := Rebind(query)
+
+	want := "SELECT id, name FROM t WHERE ($1 = '' OR name LIKE $2 OR note = 'why?' OR \"odd?col\" LIKE $3)"
+	if got != want {
+		t.Errorf("Rebind() = %q, want %q", got, want)
+	}
+	if count != 3 {
+		t.Errorf("Rebind() count = %d, want 3", count)
+	}
+}
@@ -0,0 +1,151 @@
+// Package fuzzy ranks search candidates by how closely their search field
+// values match a term, tolerating typos through edit distance
+package fuzzy
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/models"
+)
+
+// DefaultMinScore is the lowest score a candidate needs to be returned
+const DefaultMinScore = 0.6
+
+// Weights of the score components
+const (
+	similarityWeight = 0.7
+	overlapWeight    = 0.3
+)
+
+// Enabled reports whether a data type is configured for fuzzy search
+func Enabled(dtConfig *config.DataTypeConfig) bool {
+	return dtConfig.Fuzzy != nil && dtConfig.Fuzzy.Enabled
+}
+
+// MinScore returns the lowest score returned for a data type
+func MinScore(dtConfig *config.DataTypeConfig) float64 {
+	if dtConfig.Fuzzy != nil && dtConfig.Fuzzy.MinScore > 0 {
+		return dtConfig.Fuzzy.MinScore
+	}
+	return DefaultMinScore
+}
+
+// Score rates from 0 to 1 how well a candidate's search field values match
+// a term. Each term token is compared with every word of the values; the
+// score combines the average best word similarity with the share of tokens
+// matching a word exactly. An empty term scores 1.
+func Score(term string, values []string) float64 {
+	tokens := strings.Fields(strings.ToUpper(term))
+	if len(tokens) == 0 {
+		return 1
+	}
+
+	var words []string
+	for _, value := range values {
+		words = append(words, strings.Fields(strings.ToUpper(value))...)
+	}
+	if len(words) == 0 {
+		return 0
+	}
+
+	var similarity float64
+	exact := 0
+	for _, token := range tokens {
+		best := 0.0
+		for _, word := range words {
+			if word == token {
+				exact++
+				best = 1
+				break
+			}
+			if s := wordSimilarity(token, word); s > best {
+				best = s
+			}
+		}
+		similarity += best
+	}
+
+	n := float64(len(tokens))
+	score := similarityWeight*similarity/n + overlapWeight*float64(exact)/n
+	return math.Round(score*1000) / 1000
+}
+
+// wordSimilarity compares a term token with a candidate word. Words starting
+// with the token count as near matches; otherwise the edit distance to the
+// word, or to its prefix of the token's length, decides.
+func wordSimilarity(token, word string) float64 {
+	if strings.HasPrefix(word, token) {
+		return 0.95
+	}
+	if strings.Contains(word, token) {
+		return 0.85
+	}
+
+	best := similarity(token, word)
+	if len([]rune(word)) > len([]rune(token)) {
+		prefix := string([]rune(word)[:len([]rune(token))])
+		if s := similarity(token, prefix) * 0.9; s > best {
+			best = s
+		}
+	}
+	return best
+}
+
+// similarity is 1 minus the edit distance relative to the longer string
+func similarity(a, b string) float64 {
+	longest := len([]rune(a))
+	if n := len([]rune(b)); n > longest {
+		longest = n
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(Distance(a, b))/float64(longest)
+}
+
+// Distance returns the Levenshtein edit distance between two strings
+func Distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// Rank scores each item against the term using its search field values and
+// returns those scoring at least minScore, best first and at most maxResults.
+// Items with equal scores keep their original order.
+func Rank(term string, items []models.DropdownItem, values [][]string, minScore float64, maxResults int) []models.DropdownItem {
+	var ranked []models.DropdownItem
+	for i, item := range items {
+		score := Score(term, values[i])
+		if score < minScore {
+			continue
+		}
+		item.Score = score
+		ranked = append(ranked, item)
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Score > ranked[j].Score })
+	if len(ranked) > maxResults {
+		ranked = ranked[:maxResults]
+	}
+	return ranked
+}
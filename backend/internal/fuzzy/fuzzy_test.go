@@ -0,0 +1,71 @@
+package fuzzy
+
+import (
+	"testing"
+
+	"snowflake-dropdown-api/internal/models"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"ENGINERING", "ENGINEERING", 1},
+		{"KITTEN", "SITTING", 3},
+		{"MARKETING", "", 9},
+	}
+	for _, tt := range tests {
+		if got := Distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("Distance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	values := []string{"CC002", "CC002 - ENGINEERING WEST"}
+
+	exact := Score("engineering", values)
+	typo := Score("Enginering", values)
+	prefix := Score("eng", values)
+	unrelated := Score("finance", values)
+
+	if exact != 1 {
+		t.Errorf("exact score = %v, want 1", exact)
+	}
+	if typo < DefaultMinScore || typo >= exact {
+		t.Errorf("typo score = %v, want between %v and %v", typo, DefaultMinScore, exact)
+	}
+	if prefix < DefaultMinScore {
+		t.Errorf("prefix score = %v, want at least %v", prefix, DefaultMinScore)
+	}
+	if unrelated >= DefaultMinScore {
+		t.Errorf("unrelated score = %v, want below %v", unrelated, DefaultMinScore)
+	}
+	if both, one := Score("west engineering", values), Score("west finance", values); both <= one {
+		t.Errorf("two matching tokens scored %v, one matching token %v", both, one)
+	}
+}
+
+func TestRank(t *testing.T) {
+	items := []models.DropdownItem{
+		{Value: "CC001", Label: "Marketing"},
+		{Value: "CC002", Label: "Engineering"},
+		{Value: "CC003", Label: "Engine Room"},
+		{Value: "CC004", Label: "Finance"},
+	}
+	values := [][]string{{"MARKETING"}, {"ENGINEERING"}, {"ENGINE ROOM"}, {"FINANCE"}}
+
+	ranked := Rank("engine", items, values, DefaultMinScore, 10)
+	if len(ranked) != 2 || ranked[0].Value != "CC003" || ranked[1].Value != "CC002" {
+		t.Fatalf("Rank() = %+v, want CC003 then CC002", ranked)
+	}
+	if ranked[0].Score <= ranked[1].Score {
+		t.Errorf("scores = %v, %v, want descending", ranked[0].Score, ranked[1].Score)
+	}
+
+	if limited := Rank("", items, values, DefaultMinScore, 2); len(limited) != 2 || limited[0].Value != "CC001" {
+		t.Errorf("Rank() with empty term = %+v, want the first two items", limited)
+	}
+}
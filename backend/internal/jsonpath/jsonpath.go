@@ -0,0 +1,185 @@
+// Package jsonpath implements the subset of JSONPath used to map upstream
+// API responses into dropdown items: $, .name, ['name'], [n], [*] and .*
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type segmentKind int
+
+const (
+	keySegment segmentKind = iota
+	indexSegment
+	wildcardSegment
+)
+
+type segment struct {
+	kind  segmentKind
+	key   string
+	index int
+}
+
+// Path is a compiled JSONPath expression
+type Path struct {
+	expr     string
+	segments []segment
+}
+
+// Compile parses a JSONPath expression
+func Compile(expr string) (*Path, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, "$") {
+		return nil, fmt.Errorf("jsonpath %q must start with $", expr)
+	}
+
+	p := &Path{expr: expr}
+	rest := expr[1:]
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, ".*"):
+			p.segments = append(p.segments, segment{kind: wildcardSegment})
+			rest = rest[2:]
+
+		case rest[0] == '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			if key == "" {
+				return nil, fmt.Errorf("jsonpath %q: empty field name", expr)
+			}
+			p.segments = append(p.segments, segment{kind: keySegment, key: key})
+			rest = rest[end+1:]
+
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("jsonpath %q: unclosed [", expr)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			seg, err := parseBracket(inner)
+			if err != nil {
+				return nil, fmt.Errorf("jsonpath %q: %v", expr, err)
+			}
+			p.segments = append(p.segments, seg)
+			rest = rest[end+1:]
+
+		default:
+			return nil, fmt.Errorf("jsonpath %q: unexpected %q", expr, rest)
+		}
+	}
+
+	return p, nil
+}
+
+// CompileField parses a field name, which may be nested ("owner.name"), or a
+// JSONPath, both rooted at a result object
+func CompileField(field string) (*Path, error) {
+	if strings.HasPrefix(strings.TrimSpace(field), "$") {
+		return Compile(field)
+	}
+	return Compile("$." + field)
+}
+
+// parseBracket parses the contents of a [...] segment
+func parseBracket(inner string) (segment, error) {
+	if inner == "*" {
+		return segment{kind: wildcardSegment}, nil
+	}
+	if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+		return segment{kind: keySegment, key: inner[1 : len(inner)-1]}, nil
+	}
+	index, err := strconv.Atoi(inner)
+	if err != nil {
+		return segment{}, fmt.Errorf("invalid index %q", inner)
+	}
+	return segment{kind: indexSegment, index: index}, nil
+}
+
+// String returns the original expression
+func (p *Path) String() string {
+	return p.expr
+}
+
+// Get returns every value the path selects from a decoded JSON document
+func (p *Path) Get(doc interface{}) []interface{} {
+	current := []interface{}{doc}
+
+	for _, seg := range p.segments {
+		var next []interface{}
+		for _, node := range current {
+			switch seg.kind {
+			case keySegment:
+				if obj, ok := node.(map[string]interface{}); ok {
+					if value, exists := obj[seg.key]; exists {
+						next = append(next, value)
+					}
+				}
+
+			case indexSegment:
+				if arr, ok := node.([]interface{}); ok {
+					i := seg.index
+					if i < 0 {
+						i += len(arr)
+					}
+					if i >= 0 && i < len(arr) {
+						next = append(next, arr[i])
+					}
+				}
+
+			case wildcardSegment:
+				switch v := node.(type) {
+				case []interface{}:
+					next = append(next, v...)
+				case map[string]interface{}:
+					keys := make([]string, 0, len(v))
+					for key := range v {
+						keys = append(keys, key)
+					}
+					sort.Strings(keys)
+					for _, key := range keys {
+						next = append(next, v[key])
+					}
+				}
+			}
+		}
+		current = next
+	}
+
+	return current
+}
+
+// Strings returns the selected values formatted as strings
+func (p *Path) Strings(doc interface{}) []string {
+	values := p.Get(doc)
+	result := make([]string, len(values))
+	for i, value := range values {
+		result[i] = ToString(value)
+	}
+	return result
+}
+
+// ToString formats a decoded JSON scalar as a string
+func ToString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
@@ -4,8 +4,12 @@ import "time"
 
 // DropdownItem represents a single dropdown option
 type DropdownItem struct {
-	Value string `json:"value"`
-	Label string `json:"label"`
+	Value string  `json:"value"`
+	Label string  `json:"label"`
+	Score float64 `json:"score,omitempty"` // relevance from 0 to 1, set in fuzzy mode
+
+	// Attributes holds the data type's extra columns that have a value
+	Attributes map[string]string `json:"attributes,omitempty"`
 }
 
 // DropdownResponse represents the API response
@@ -20,14 +24,52 @@ type Metadata struct {
 	RowCount   int       `json:"row_count"`
 	Source     string    `json:"source"`
 	Cached     bool      `json:"cached"`
+	Stale      bool      `json:"stale"` // served from an expired cache entry
+	NextCursor string    `json:"next_cursor,omitempty"`
+	HasMore    bool      `json:"has_more"`
+	Truncated  bool      `json:"truncated,omitempty"` // more rows may match than the data type can return
+}
+
+// ResolveRequest lists the values to resolve to items
+type ResolveRequest struct {
+	Values []string `json:"values"`
+}
+
+// ResolveResponse holds the items found for resolved values, in request
+// order, and the values that matched no item
+type ResolveResponse struct {
+	Data    []DropdownItem `json:"data"`
+	Unknown []string       `json:"unknown"`
+}
+
+// ValidateRequest lists the values to validate
+type ValidateRequest struct {
+	Values []string `json:"values"`
 }
 
 // DataTypeInfo represents information about a data type for the frontend
 type DataTypeInfo struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Icon        string `json:"icon"`
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Icon        string          `json:"icon"`
+	Params      []ParamInfo     `json:"params,omitempty"`
+	Attributes  []AttributeInfo `json:"attributes,omitempty"`
+}
+
+// AttributeInfo describes an item attribute for display
+type AttributeInfo struct {
+	Name    string `json:"name"`
+	Label   string `json:"label"`
+	Display string `json:"display"`
+}
+
+// ParamInfo describes a context parameter the frontend passes with searches
+type ParamInfo struct {
+	Name      string `json:"name"`
+	DependsOn string `json:"dependsOn,omitempty"`
+	Required  bool   `json:"required"`
+	Cascade   string `json:"cascade"`
 }
 
 // ConfigResponse represents the configuration response for the frontend
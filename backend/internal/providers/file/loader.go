@@ -0,0 +1,170 @@
+package file
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/models"
+)
+
+// format returns the file format, inferring it from the extension if unset
+func format(src *config.FileSourceConfig) (string, error) {
+	f := strings.ToLower(src.Format)
+	if f == "" {
+		f = strings.TrimPrefix(strings.ToLower(filepath.Ext(filePath(src))), ".")
+	}
+
+	switch f {
+	case "csv", "json":
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported file format '%s' (use csv or json)", f)
+	}
+}
+
+// filePath returns the data file path with any env: reference resolved
+func filePath(src *config.FileSourceConfig) string {
+	return config.ResolveValue(src.Path)
+}
+
+// load reads the data type's file and builds its in-memory index
+func load(dtConfig *config.DataTypeConfig) (*dataset, error) {
+	src := dtConfig.File
+
+	f, err := format(src)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(filePath(src))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	var records []map[string]string
+	if f == "csv" {
+		records, err = readCSV(file)
+	} else {
+		records, err = readJSON(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", src.Path, err)
+	}
+
+	searchFields := dtConfig.SearchFields
+	if len(searchFields) == 0 {
+		searchFields = []string{src.ValueColumn, src.LabelColumn}
+	}
+
+	ds := &dataset{
+		byValue: make(map[string]int, len(records)),
+		modTime: info.ModTime(),
+	}
+	for _, record := range records {
+		value := record[src.ValueColumn]
+		if value == "" {
+			continue
+		}
+		if _, duplicate := ds.byValue[value]; duplicate {
+			continue
+		}
+
+		fields := make([]string, len(searchFields))
+		for i, name := range searchFields {
+			fields[i] = strings.ToUpper(record[name])
+		}
+
+		item := models.DropdownItem{Value: value, Label: record[src.LabelColumn]}
+		for _, attribute := range dtConfig.Attributes {
+			if v := record[attribute.ColumnName()]; v != "" {
+				if item.Attributes == nil {
+					item.Attributes = make(map[string]string, len(dtConfig.Attributes))
+				}
+				item.Attributes[attribute.Name] = v
+			}
+		}
+
+		ds.byValue[value] = len(ds.items)
+		ds.items = append(ds.items, item)
+		ds.search = append(ds.search, fields)
+	}
+
+	return ds, nil
+}
+
+// readCSV reads a CSV file whose first row holds the column names
+func readCSV(r io.Reader) ([]map[string]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+	// Excel exports often start with a byte order mark
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	var records []map[string]string
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		record := make(map[string]string, len(header))
+		for i, name := range header {
+			if i < len(row) {
+				record[name] = strings.TrimSpace(row[i])
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// readJSON reads a JSON array of objects, converting values to strings
+func readJSON(r io.Reader) ([]map[string]string, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+
+	var raw []map[string]interface{}
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	records := make([]map[string]string, 0, len(raw))
+	for _, obj := range raw {
+		record := make(map[string]string, len(obj))
+		for key, value := range obj {
+			if value != nil {
+				record[key] = fmt.Sprint(value)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
@@ -0,0 +1,239 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/models"
+	"snowflake-dropdown-api/internal/providers"
+	"snowflake-dropdown-api/internal/search"
+)
+
+const defaultReloadInterval = 30 * time.Second
+
+func init() {
+	providers.Register("file", func() providers.DataProvider {
+		return &Provider{
+			datasets: make(map[string]*dataset),
+			stop:     make(chan struct{}),
+		}
+	})
+}
+
+// Provider serves data types from CSV or JSON files held in memory.
+// Files are re-read automatically when their modification time changes.
+type Provider struct {
+	mu       sync.RWMutex
+	datasets map[string]*dataset // keyed by data type ID
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// dataset is the in-memory index of one file
+type dataset struct {
+	items   []models.DropdownItem
+	search  [][]string // upper-cased search field values per item
+	byValue map[string]int
+	modTime time.Time
+}
+
+// Connect loads each file and starts watching it for changes
+func (p *Provider) Connect(ctx context.Context, dataTypes []config.DataTypeConfig) error {
+	for _, dt := range dataTypes {
+		ds, err := load(&dt)
+		if err != nil {
+			return fmt.Errorf("data type '%s': %v", dt.ID, err)
+		}
+
+		p.mu.Lock()
+		p.datasets[dt.ID] = ds
+		p.mu.Unlock()
+
+		log.Printf("Loaded %d items for %s from %s", len(ds.items), dt.ID, dt.File.Path)
+		go p.watch(dt)
+	}
+	return nil
+}
+
+// Search returns items where any search field contains the term
+func (p *Provider) Search(ctx context.Context, dtConfig *config.DataTypeConfig, searchTerm string) ([]models.DropdownItem, error) {
+	ds, err := p.dataset(dtConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	maxResults := config.GetMaxResults()
+	term := strings.ToUpper(strings.TrimSpace(searchTerm))
+
+	var items []models.DropdownItem
+	for i, item := range ds.items {
+		if len(items) >= maxResults {
+			break
+		}
+		if term == "" || matches(ds.search[i], term) {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// Lookup returns the item with exactly the given value
+func (p *Provider) Lookup(ctx context.Context, dtConfig *config.DataTypeConfig, value string) (*models.DropdownItem, error) {
+	ds, err := p.dataset(dtConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	i, exists := ds.byValue[value]
+	if !exists {
+		return nil, providers.ErrNotFound
+	}
+	item := ds.items[i]
+	return &item, nil
+}
+
+// LoadAll returns every item of the file with its search field values, so
+// snapshots and fuzzy candidates are not cut at maxResults. Search field
+// values are upper-cased.
+func (p *Provider) LoadAll(ctx context.Context, dtConfig *config.DataTypeConfig) ([]providers.Record, error) {
+	ds, err := p.dataset(dtConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]providers.Record, len(ds.items))
+	for i, item := range ds.items {
+		fields := map[string]string{"value": item.Value, "label": item.Label}
+		for j, name := range dtConfig.SearchFields {
+			fields[strings.ToLower(name)] = ds.search[i][j]
+		}
+		records[i] = providers.Record{Item: item, Fields: fields}
+	}
+	return records, nil
+}
+
+// ValidateConfig checks the file settings
+func (p *Provider) ValidateConfig(dtConfig *config.DataTypeConfig) error {
+	src := dtConfig.File
+	if src == nil || src.Path == "" {
+		return fmt.Errorf("file.path is required")
+	}
+	if src.ValueColumn == "" || src.LabelColumn == "" {
+		return fmt.Errorf("file.valueColumn and file.labelColumn are required")
+	}
+	if _, err := format(src); err != nil {
+		return err
+	}
+	if len(dtConfig.Params) > 0 {
+		return fmt.Errorf("params are not supported by the file provider")
+	}
+	if _, err := os.Stat(filePath(src)); err != nil {
+		return fmt.Errorf("data file: %v", err)
+	}
+	return nil
+}
+
+// Close stops watching files
+func (p *Provider) Close() error {
+	p.stopOnce.Do(func() { close(p.stop) })
+	return nil
+}
+
+// dataset returns the loaded data for a data type, loading it and starting
+// its watcher on first use
+func (p *Provider) dataset(dtConfig *config.DataTypeConfig) (*dataset, error) {
+	p.mu.RLock()
+	ds, exists := p.datasets[dtConfig.ID]
+	p.mu.RUnlock()
+	if exists {
+		return ds, nil
+	}
+
+	if dtConfig.File == nil {
+		return nil, fmt.Errorf("file settings missing for data type '%s'", dtConfig.ID)
+	}
+
+	ds, err := load(dtConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the first dataset stored so only one watcher runs per file
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if current, exists := p.datasets[dtConfig.ID]; exists {
+		return current, nil
+	}
+	p.datasets[dtConfig.ID] = ds
+	go p.watch(*dtConfig)
+	return ds, nil
+}
+
+// watch reloads the file whenever its modification time changes
+func (p *Provider) watch(dt config.DataTypeConfig) {
+	interval := defaultReloadInterval
+	if dt.File.ReloadSeconds > 0 {
+		interval = time.Duration(dt.File.ReloadSeconds) * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			if err := p.reloadIfChanged(&dt); err != nil {
+				log.Printf("Error reloading %s for %s, keeping previous data: %v", dt.File.Path, dt.ID, err)
+			}
+		}
+	}
+}
+
+// reloadIfChanged replaces the dataset if the file has been modified and
+// drops the data type's cached results
+func (p *Provider) reloadIfChanged(dt *config.DataTypeConfig) error {
+	info, err := os.Stat(filePath(dt.File))
+	if err != nil {
+		return err
+	}
+
+	p.mu.RLock()
+	current := p.datasets[dt.ID]
+	p.mu.RUnlock()
+	if current != nil && info.ModTime().Equal(current.modTime) {
+		return nil
+	}
+
+	ds, err := load(dt)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.datasets[dt.ID] = ds
+	p.mu.Unlock()
+
+	// Cached searches and lookups hold the previous rows
+	search.InvalidateCache(dt.ID)
+
+	log.Printf("Reloaded %d items for %s from %s", len(ds.items), dt.ID, dt.File.Path)
+	return nil
+}
+
+// matches reports whether any search field contains the upper-cased term
+func matches(fields []string, term string) bool {
+	for _, field := range fields {
+		if strings.Contains(field, term) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"snowflake-dropdown-api/internal/cache"
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/models"
+	"snowflake-dropdown-api/internal/search"
+)
+
+func newTestProvider() *Provider {
+	return &Provider{datasets: make(map[string]*dataset), stop: make(chan struct{})}
+}
+
+func TestSearchCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "offices.csv")
+	csv := "code,city,country\nBER,Berlin,Germany\nNYC,New York,USA\nSEA,Seattle,USA\n"
+	if err := os.WriteFile(path, []byte(csv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	dt := &config.DataTypeConfig{
+		ID:           "offices",
+		SearchFields: []string{"city", "country"},
+		File:         &config.FileSourceConfig{Path: path, ValueColumn: "code", LabelColumn: "city"},
+	}
+	p := newTestProvider()
+	defer p.Close()
+
+	if err := p.ValidateConfig(dt); err != nil {
+		t.Fatalf("ValidateConfig() error = %v", err)
+	}
+
+	items, err := p.Search(context.Background(), dt, "usa")
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(items) != 2 || items[0].Value != "NYC" || items[1].Label != "Seattle" {
+		t.Errorf("Search() = %v, want NYC and SEA", items)
+	}
+
+	item, err := p.Lookup(context.Background(), dt, "BER")
+	if err != nil || item.Label != "Berlin" {
+		t.Errorf("Lookup() = %v, %v, want Berlin", item, err)
+	}
+}
+
+func TestReloadOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trains.json")
+	if err := os.WriteFile(path, []byte(`[{"id": 1, "name": "Alpha"}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	dt := &config.DataTypeConfig{
+		ID:   "trains",
+		File: &config.FileSourceConfig{Path: path, ValueColumn: "id", LabelColumn: "name"},
+	}
+	p := newTestProvider()
+	defer p.Close()
+
+	if item, err := p.Lookup(context.Background(), dt, "1"); err != nil || item.Label != "Alpha" {
+		t.Fatalf("Lookup() = %v, %v, want Alpha", item, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`[{"id": 1, "name": "Alpha"}, {"id": 2, "name": "Beta"}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	key := search.CachePrefix(dt.ID) + "alpha"
+	cache.Instance.Set(key, models.DropdownResponse{Data: []models.DropdownItem{{Value: "1", Label: "Alpha"}}})
+
+	if err := p.reloadIfChanged(dt); err != nil {
+		t.Fatalf("reloadIfChanged() error = %v", err)
+	}
+	items, _ := p.Search(context.Background(), dt, "")
+	if len(items) != 2 {
+		t.Errorf("after reload got %d items, want 2", len(items))
+	}
+	if _, found := cache.Instance.Get(key); found {
+		t.Error("cached search survived the reload")
+	}
+}
+
+func TestEnvPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "offices.csv")
+	if err := os.WriteFile(path, []byte("code,city\nBER,Berlin\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("OFFICES_FILE", path)
+
+	dt := &config.DataTypeConfig{
+		ID:   "offices",
+		File: &config.FileSourceConfig{Path: "env:OFFICES_FILE", ValueColumn: "code", LabelColumn: "city"},
+	}
+	p := newTestProvider()
+	defer p.Close()
+
+	if err := p.ValidateConfig(dt); err != nil {
+		t.Fatalf("ValidateConfig() error = %v", err)
+	}
+	if item, err := p.Lookup(context.Background(), dt, "BER"); err != nil || item.Label != "Berlin" {
+		t.Errorf("Lookup() = %v, %v, want Berlin", item, err)
+	}
+}
+
+func TestLazyLoadWatches(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trains.json")
+	if err := os.WriteFile(path, []byte(`[{"id": 1, "name": "Alpha"}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	dt := &config.DataTypeConfig{
+		ID:   "trains",
+		File: &config.FileSourceConfig{Path: path, ValueColumn: "id", LabelColumn: "name", ReloadSeconds: 1},
+	}
+	p := newTestProvider()
+	defer p.Close()
+
+	// Loaded on first use rather than by Connect
+	if _, err := p.Lookup(context.Background(), dt, "1"); err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(`[{"id": 1, "name": "Alpha"}, {"id": 2, "name": "Beta"}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := p.Lookup(context.Background(), dt, "2"); err == nil {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Error("lazily loaded file was not reloaded after it changed")
+}
@@ -0,0 +1,200 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/jsonpath"
+	"snowflake-dropdown-api/internal/models"
+	"snowflake-dropdown-api/internal/providers"
+)
+
+func init() {
+	providers.Register("graphql", func() providers.DataProvider {
+		return &Provider{client: &http.Client{}}
+	})
+}
+
+// Provider runs a GraphQL query per search and maps the selected result
+// objects into dropdown items
+type Provider struct {
+	client *http.Client
+}
+
+// request is a GraphQL request body
+type request struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// Connect has nothing to prepare; upstream APIs are called per request
+func (p *Provider) Connect(ctx context.Context, dataTypes []config.DataTypeConfig) error {
+	return nil
+}
+
+// Search runs the configured query with the search term as $search and each
+// context parameter as a variable of its name, null when it has no value
+func (p *Provider) Search(ctx context.Context, dtConfig *config.DataTypeConfig, searchTerm string) ([]models.DropdownItem, error) {
+	gc := dtConfig.GraphQL
+	if gc == nil {
+		return nil, fmt.Errorf("graphql settings missing for data type '%s'", dtConfig.ID)
+	}
+
+	variables := make(map[string]interface{}, len(gc.Variables)+len(dtConfig.Params)+1)
+	for key, value := range gc.Variables {
+		variables[key] = providers.ExpandParam(value, dtConfig, searchTerm)
+	}
+	for _, param := range dtConfig.Params {
+		if value := dtConfig.ContextValues[param.Name]; value != "" {
+			variables[param.Name] = value
+		} else {
+			variables[param.Name] = nil
+		}
+	}
+	variables["search"] = searchTerm
+
+	payload, err := json.Marshal(request{Query: gc.Query, Variables: variables})
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := providers.DoWithRetry(ctx, p.client, gc.Retries, providers.UpstreamTimeout(gc.TimeoutSeconds),
+		func(ctx context.Context) (*http.Request, error) {
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, config.ResolveValue(gc.Endpoint), bytes.NewReader(payload))
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept", "application/json")
+			for key, value := range gc.Headers {
+				req.Header.Set(key, config.ResolveValue(value))
+			}
+			return req, providers.ApplyAuth(req, gc.Auth)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := MapItems(body, gc, dtConfig.Attributes)
+	if err != nil {
+		return nil, &providers.UpstreamError{StatusCode: http.StatusOK, Err: err}
+	}
+
+	if maxResults := config.GetMaxResults(); len(items) > maxResults {
+		items = items[:maxResults]
+	}
+	return items, nil
+}
+
+// Lookup searches for the value and returns the exact match
+func (p *Provider) Lookup(ctx context.Context, dtConfig *config.DataTypeConfig, value string) (*models.DropdownItem, error) {
+	return providers.LookupBySearch(ctx, p, dtConfig, value)
+}
+
+// ValidateConfig checks the endpoint, query document and result mapping
+func (p *Provider) ValidateConfig(dtConfig *config.DataTypeConfig) error {
+	gc := dtConfig.GraphQL
+	if gc == nil || gc.Endpoint == "" {
+		return fmt.Errorf("graphql.endpoint is required")
+	}
+	if !strings.Contains(gc.Query, "$search") {
+		return fmt.Errorf("graphql.query must declare a $search variable")
+	}
+	if gc.ValueField == "" || gc.LabelField == "" {
+		return fmt.Errorf("graphql.valueField and graphql.labelField are required")
+	}
+	if _, _, _, err := compile(gc); err != nil {
+		return err
+	}
+	for _, attribute := range dtConfig.Attributes {
+		if _, err := jsonpath.CompileField(attribute.ColumnName()); err != nil {
+			return fmt.Errorf("attribute %s: %v", attribute.Name, err)
+		}
+	}
+	return providers.ValidateAuth(gc.Auth)
+}
+
+// Close releases idle upstream connections
+func (p *Provider) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
+
+// MapItems decodes a GraphQL response and maps each result object to an
+// item, reading each attribute from the field its column names
+func MapItems(body []byte, gc *config.GraphQLConfig, attributes []config.AttributeConfig) ([]models.DropdownItem, error) {
+	resultPath, valuePath, labelPath, err := compile(gc)
+	if err != nil {
+		return nil, err
+	}
+	attributePaths := make([]*jsonpath.Path, len(attributes))
+	for i, attribute := range attributes {
+		if attributePaths[i], err = jsonpath.CompileField(attribute.ColumnName()); err != nil {
+			return nil, err
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var resp struct {
+		Data   interface{} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := decoder.Decode(&resp); err != nil {
+		return nil, fmt.Errorf("invalid GraphQL response: %v", err)
+	}
+	if len(resp.Errors) > 0 {
+		return nil, fmt.Errorf("GraphQL error: %s", resp.Errors[0].Message)
+	}
+
+	doc := map[string]interface{}{"data": resp.Data}
+	results := resultPath.Get(doc)
+	items := make([]models.DropdownItem, 0, len(results))
+	for _, result := range results {
+		values := valuePath.Strings(result)
+		labels := labelPath.Strings(result)
+		if len(values) == 0 {
+			continue
+		}
+
+		item := models.DropdownItem{Value: values[0]}
+		if len(labels) > 0 {
+			item.Label = labels[0]
+		}
+		for i, path := range attributePaths {
+			if found := path.Strings(result); len(found) > 0 && found[0] != "" {
+				if item.Attributes == nil {
+					item.Attributes = make(map[string]string, len(attributes))
+				}
+				item.Attributes[attributes[i].Name] = found[0]
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// compile parses the result path and the value/label fields, which are
+// relative to each result object and may be nested ("owner.name")
+func compile(gc *config.GraphQLConfig) (resultPath, valuePath, labelPath *jsonpath.Path, err error) {
+	resultExpr := gc.ResultPath
+	if resultExpr == "" {
+		resultExpr = "$.data.*[*]"
+	}
+	if resultPath, err = jsonpath.Compile(resultExpr); err != nil {
+		return
+	}
+	if valuePath, err = jsonpath.CompileField(gc.ValueField); err != nil {
+		return
+	}
+	labelPath, err = jsonpath.CompileField(gc.LabelField)
+	return
+}
@@ -0,0 +1,86 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/providers"
+)
+
+func TestSearchSendsVariablesAndMapsResults(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Variables["search"] != "acme" {
+			t.Errorf("search variable = %v, want acme", req.Variables["search"])
+		}
+		if req.Variables["first"] != float64(100) {
+			t.Errorf("first variable = %v, want 100", req.Variables["first"])
+		}
+		fmt.Fprint(w, `{"data": {"vendors": {"nodes": [
+			{"code": "V1", "profile": {"name": "Acme Supplies"}},
+			{"code": "V2", "profile": {"name": "Acme Tools"}}
+		]}}}`)
+	}))
+	defer server.Close()
+
+	dt := &config.DataTypeConfig{
+		ID:       "vendors",
+		Provider: "graphql",
+		GraphQL: &config.GraphQLConfig{
+			Endpoint:   server.URL,
+			Query:      "query Vendors($search: String!, $first: Int) { vendors(filter: $search, first: $first) { nodes { code profile { name } } } }",
+			Variables:  map[string]interface{}{"first": "{maxResults}"},
+			ResultPath: "$.data.vendors.nodes[*]",
+			ValueField: "code",
+			LabelField: "profile.name",
+		},
+	}
+	p := &Provider{client: server.Client()}
+	if err := p.ValidateConfig(dt); err != nil {
+		t.Fatalf("ValidateConfig() error = %v", err)
+	}
+
+	items, err := p.Search(context.Background(), dt, "acme")
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(items) != 2 || items[1].Value != "V2" || items[1].Label != "Acme Tools" {
+		t.Errorf("Search() = %v", items)
+	}
+}
+
+func TestSearchReportsGraphQLErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"errors": [{"message": "field 'vendors' not found"}]}`)
+	}))
+	defer server.Close()
+
+	dt := &config.DataTypeConfig{
+		ID: "vendors",
+		GraphQL: &config.GraphQLConfig{
+			Endpoint:   server.URL,
+			Query:      "query ($search: String!) { vendors(filter: $search) { code name } }",
+			ValueField: "code",
+			LabelField: "name",
+		},
+	}
+	p := &Provider{client: server.Client()}
+
+	_, err := p.Search(context.Background(), dt, "x")
+	if err == nil {
+		t.Fatal("Search() error = nil, want GraphQL error")
+	}
+	if got := providers.HTTPStatusFor(err); got != http.StatusBadGateway {
+		t.Errorf("HTTPStatusFor() = %d, want 502", got)
+	}
+}
@@ -0,0 +1,102 @@
+package providers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/database"
+	"snowflake-dropdown-api/internal/models"
+)
+
+// ErrInvalidCursor is returned for cursors that cannot be decoded or do not
+// fit the data type's pagination
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the position after the last item of a page. Keyset cursors hold
+// the last row's order columns, ending with its value, others an offset into
+// the results.
+type Cursor struct {
+	Keys   []*string `json:"ks,omitempty"`
+	Offset int       `json:"o,omitempty"`
+}
+
+// Encode returns the cursor as an opaque URL-safe string
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor returned by Encode
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Offset < 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// Page selects a page of search results
+type Page struct {
+	Limit int
+	After *Cursor // nil for the first page
+}
+
+// Pager is implemented by providers that page through search results with
+// keyset pagination
+type Pager interface {
+	SearchPage(ctx context.Context, dtConfig *config.DataTypeConfig, searchTerm string, page Page) ([]models.DropdownItem, *Cursor, error)
+}
+
+// RowsFunc runs a query and returns its rows keyed by lower-cased column name
+type RowsFunc func(ctx context.Context, query string, params []interface{}) ([]map[string]string, error)
+
+// Keyset reports whether a data type's provider pages its search results
+// with keyset pagination, which requires a generated query
+func Keyset(dtConfig *config.DataTypeConfig) bool {
+	if !database.Structured(dtConfig) {
+		return false
+	}
+	provider, err := ForDataType(dtConfig)
+	if err != nil {
+		return false
+	}
+	_, ok := provider.(Pager)
+	return ok
+}
+
+// SearchPageSQL runs the page query of a structured data type, returning the
+// page's items and the cursor of the next page, or nil on the last page
+func SearchPageSQL(ctx context.Context, dtConfig *config.DataTypeConfig, searchTerm string, page Page, rows RowsFunc) ([]models.DropdownItem, *Cursor, error) {
+	var after *database.Keyset
+	if page.After != nil {
+		after = &database.Keyset{Keys: page.After.Keys}
+		if !database.KeysetFits(dtConfig, *after) {
+			return nil, nil, ErrInvalidCursor
+		}
+	}
+
+	query, params := database.BuildStructuredPageQuery(dtConfig, searchTerm, page.Limit, after)
+	result, err := rows(ctx, query, params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var next *Cursor
+	if len(result) > page.Limit {
+		result = result[:page.Limit]
+		next = &Cursor{Keys: database.KeysetFromRow(dtConfig, result[len(result)-1]).Keys}
+	}
+
+	items := make([]models.DropdownItem, len(result))
+	for i, row := range result {
+		items[i] = ItemFromRow(dtConfig, row)
+	}
+	return items, next, nil
+}
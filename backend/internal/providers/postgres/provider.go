@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"time"
+
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/database"
+	"snowflake-dropdown-api/internal/providers"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+func init() {
+	providers.Register("postgres", func() providers.DataProvider {
+		return newProvider()
+	})
+}
+
+// Provider serves data types from PostgreSQL. Data types sharing the same
+// connection settings share a single connection pool.
+type Provider struct {
+	providers.SQLProvider
+	pools *database.Pools
+}
+
+// newProvider returns a provider with its query methods bound to its pools,
+// rewriting placeholders as $n
+func newProvider() *Provider {
+	p := &Provider{pools: database.NewPools("pgx")}
+	p.SQLProvider = providers.SQLProvider{DB: p.pool, Bind: database.Rebind}
+	return p
+}
+
+// Connect opens and verifies a pool for each distinct connection
+func (p *Provider) Connect(ctx context.Context, dataTypes []config.DataTypeConfig) error {
+	for i := range dataTypes {
+		dt := &dataTypes[i]
+
+		db, err := p.pool(dt)
+		if err != nil {
+			return fmt.Errorf("data type '%s': %v", dt.ID, err)
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		err = db.PingContext(pingCtx)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to connect to PostgreSQL for data type '%s': %v", dt.ID, err)
+		}
+	}
+
+	log.Println("Successfully connected to PostgreSQL")
+	return nil
+}
+
+// ValidateConfig checks the data type query and connection settings
+func (p *Provider) ValidateConfig(dtConfig *config.DataTypeConfig) error {
+	if err := database.ValidateSearchQuery(dtConfig); err != nil {
+		return err
+	}
+	if err := database.ValidatePlaceholders(dtConfig); err != nil {
+		return err
+	}
+	if err := database.ValidateDatasetQuery(dtConfig); err != nil {
+		return err
+	}
+
+	if conn := dtConfig.Connection; conn != nil {
+		if conn.MaxOpenConns < 0 || conn.MaxIdleConns < 0 || conn.ConnMaxLifetimeMinutes < 0 {
+			return fmt.Errorf("connection pool settings must not be negative")
+		}
+		if conn.DSN != "" && config.ResolveValue(conn.DSN) == "" {
+			return fmt.Errorf("connection dsn %s is empty", conn.DSN)
+		}
+	}
+
+	return nil
+}
+
+// Close closes all connection pools
+func (p *Provider) Close() error {
+	return p.pools.Close()
+}
+
+// pool returns the connection pool for a data type
+func (p *Provider) pool(dtConfig *config.DataTypeConfig) (*sql.DB, error) {
+	return p.pools.Get(ConnectionString(dtConfig.Connection), dtConfig.Connection)
+}
+
+// ConnectionString builds a PostgreSQL URL from the connection settings.
+// Unset settings fall back to the standard PG* environment variables.
+func ConnectionString(conn *config.ConnectionConfig) string {
+	if conn == nil {
+		return ""
+	}
+	if conn.DSN != "" {
+		return config.ResolveValue(conn.DSN)
+	}
+
+	u := url.URL{Scheme: "postgres"}
+
+	host := config.ResolveValue(conn.Host)
+	if conn.Port > 0 {
+		host += ":" + strconv.Itoa(conn.Port)
+	}
+	u.Host = host
+
+	if user := config.ResolveValue(conn.User); user != "" {
+		if password := config.ResolveValue(conn.Password); password != "" {
+			u.User = url.UserPassword(user, password)
+		} else {
+			u.User = url.User(user)
+		}
+	}
+
+	if db := config.ResolveValue(conn.Database); db != "" {
+		u.Path = "/" + db
+	}
+
+	if conn.SSLMode != "" {
+		u.RawQuery = url.Values{"sslmode": {conn.SSLMode}}.Encode()
+	}
+
+	if u.Host == "" && u.User == nil && u.Path == "" && u.RawQuery == "" {
+		return ""
+	}
+	return u.String()
+}
@@ -0,0 +1,313 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/database"
+	"snowflake-dropdown-api/internal/models"
+)
+
+// DefaultProvider is used for data types that do not set a provider
+const DefaultProvider = "snowflake"
+
+// ErrNotFound is returned by Lookup when no item matches the value
+var ErrNotFound = errors.New("item not found")
+
+// Searcher is implemented by anything that can search a data type, such as
+// a DataProvider or the SQLProvider it embeds
+type Searcher interface {
+	Search(ctx context.Context, dtConfig *config.DataTypeConfig, searchTerm string) ([]models.DropdownItem, error)
+}
+
+// DataProvider is a backend capable of serving one or more data types
+type DataProvider interface {
+	// Connect prepares the provider to serve the given data types
+	Connect(ctx context.Context, dataTypes []config.DataTypeConfig) error
+
+	// Search returns the items matching the search term
+	Search(ctx context.Context, dtConfig *config.DataTypeConfig, searchTerm string) ([]models.DropdownItem, error)
+
+	// Lookup returns the item with exactly the given value
+	Lookup(ctx context.Context, dtConfig *config.DataTypeConfig, value string) (*models.DropdownItem, error)
+
+	// ValidateConfig checks that a data type is usable with this provider
+	ValidateConfig(dtConfig *config.DataTypeConfig) error
+
+	// Close releases any connections held by the provider
+	Close() error
+}
+
+// QueryExecutor is implemented by providers that can run raw SELECT queries
+type QueryExecutor interface {
+	Query(ctx context.Context, query string, params []interface{}) ([]models.DropdownItem, error)
+}
+
+// Factory creates a new provider instance
+type Factory func() DataProvider
+
+var (
+	mu        sync.Mutex
+	factories = make(map[string]Factory)
+	instances = make(map[string]DataProvider)
+)
+
+// Register makes a provider available under the given name.
+// It panics if called twice with the same name.
+func Register(name string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if factory == nil {
+		panic("providers: Register factory is nil")
+	}
+	if _, exists := factories[name]; exists {
+		panic("providers: Register called twice for provider " + name)
+	}
+	factories[name] = factory
+}
+
+// Names returns the names of all registered providers
+func Names() []string {
+	mu.Lock()
+	defer mu.Unlock()
+
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the shared instance of the named provider
+func Get(name string) (DataProvider, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if provider, exists := instances[name]; exists {
+		return provider, nil
+	}
+
+	factory, exists := factories[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown provider '%s'", name)
+	}
+
+	provider := factory()
+	instances[name] = provider
+	return provider, nil
+}
+
+// NameFor returns the provider name configured for a data type
+func NameFor(dtConfig *config.DataTypeConfig) string {
+	if dtConfig.Provider == "" {
+		return DefaultProvider
+	}
+	return dtConfig.Provider
+}
+
+// ForDataType returns the provider serving a data type
+func ForDataType(dtConfig *config.DataTypeConfig) (DataProvider, error) {
+	return Get(NameFor(dtConfig))
+}
+
+// LookupSQL runs a data type's lookup query, falling back to LookupBySearch
+// for data types without one
+func LookupSQL(ctx context.Context, provider Searcher, dtConfig *config.DataTypeConfig, value string, rows RowsFunc) (*models.DropdownItem, error) {
+	query, params, ok := database.BuildLookupQuery(dtConfig, value)
+	if !ok {
+		return LookupBySearch(ctx, provider, dtConfig, value)
+	}
+
+	result, err := rows(ctx, query, params)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range result {
+		if row["value"] == value {
+			item := ItemFromRow(dtConfig, row)
+			return &item, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// lookupBatchSize bounds the values looked up by one batch query
+const lookupBatchSize = 100
+
+// BatchLookuper is implemented by providers that can look up many values
+// with one query per batch
+type BatchLookuper interface {
+	// LookupMany returns the items with exactly the given values, keyed by
+	// value. Values without an item are left out.
+	LookupMany(ctx context.Context, dtConfig *config.DataTypeConfig, values []string) (map[string]models.DropdownItem, error)
+}
+
+// ErrNoLookupQuery is returned by batch lookups of data types that have
+// neither a lookup query nor a table to look values up in
+var ErrNoLookupQuery = errors.New("no lookupQuery or table to look values up in")
+
+// LookupManySQL looks values up in batches with the data type's lookup
+// query, or for structured data types its table
+func LookupManySQL(ctx context.Context, dtConfig *config.DataTypeConfig, values []string, rows RowsFunc) (map[string]models.DropdownItem, error) {
+	found := make(map[string]models.DropdownItem, len(values))
+	for start := 0; start < len(values); start += lookupBatchSize {
+		batch := values[start:min(start+lookupBatchSize, len(values))]
+		query, params, ok := database.BuildBatchLookupQuery(dtConfig, batch)
+		if !ok {
+			return nil, fmt.Errorf("data type '%s': %w", dtConfig.ID, ErrNoLookupQuery)
+		}
+
+		result, err := rows(ctx, query, params)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range result {
+			if _, exists := found[row["value"]]; !exists {
+				found[row["value"]] = ItemFromRow(dtConfig, row)
+			}
+		}
+	}
+	return found, nil
+}
+
+// StatusChecker is implemented by providers that can run a data type's
+// status query
+type StatusChecker interface {
+	// LookupStatus returns the item with exactly the given value and its
+	// status, or ErrNotFound
+	LookupStatus(ctx context.Context, dtConfig *config.DataTypeConfig, value string) (*models.DropdownItem, string, error)
+}
+
+// ItemStatus is an item found by a status query and its status
+type ItemStatus struct {
+	Item   models.DropdownItem
+	Status string
+}
+
+// BatchStatusChecker is implemented by providers that can run a data type's
+// status query for many values with one query per batch
+type BatchStatusChecker interface {
+	// LookupStatusMany returns the items with exactly the given values and
+	// their statuses, keyed by value. Values without an item are left out.
+	LookupStatusMany(ctx context.Context, dtConfig *config.DataTypeConfig, values []string) (map[string]ItemStatus, error)
+}
+
+// CanLookupMany reports whether a SQL data type has a lookup query or a
+// table to look values up in batches
+func CanLookupMany(dtConfig *config.DataTypeConfig) bool {
+	return dtConfig.LookupQuery != "" || database.Structured(dtConfig)
+}
+
+// LookupStatusManySQL runs a data type's status query for values in batches
+func LookupStatusManySQL(ctx context.Context, dtConfig *config.DataTypeConfig, values []string, rows RowsFunc) (map[string]ItemStatus, error) {
+	if dtConfig.Status == nil || dtConfig.Status.Query == "" {
+		return nil, fmt.Errorf("data type '%s' has no status query", dtConfig.ID)
+	}
+
+	found := make(map[string]ItemStatus, len(values))
+	for start := 0; start < len(values); start += lookupBatchSize {
+		batch := values[start:min(start+lookupBatchSize, len(values))]
+		query, params := database.UnionPerValue(dtConfig.Status.Query, batch)
+		result, err := rows(ctx, query, params)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range result {
+			if _, exists := found[row["value"]]; !exists {
+				found[row["value"]] = ItemStatus{Item: ItemFromRow(dtConfig, row), Status: row["status"]}
+			}
+		}
+	}
+	return found, nil
+}
+
+// LookupStatusSQL runs a data type's status query for a value
+func LookupStatusSQL(ctx context.Context, dtConfig *config.DataTypeConfig, value string, rows RowsFunc) (*models.DropdownItem, string, error) {
+	if dtConfig.Status == nil || dtConfig.Status.Query == "" {
+		return nil, "", fmt.Errorf("data type '%s' has no status query", dtConfig.ID)
+	}
+
+	result, err := rows(ctx, dtConfig.Status.Query, []interface{}{value})
+	if err != nil {
+		return nil, "", err
+	}
+	for _, row := range result {
+		if row["value"] == value {
+			item := ItemFromRow(dtConfig, row)
+			return &item, row["status"], nil
+		}
+	}
+	return nil, "", ErrNotFound
+}
+
+// ItemFromRow returns the item of a query row keyed by lower-cased column
+// name, with the data type's attributes
+func ItemFromRow(dtConfig *config.DataTypeConfig, row map[string]string) models.DropdownItem {
+	return models.DropdownItem{
+		Value:      row["value"],
+		Label:      row["label"],
+		Attributes: database.RowAttributes(dtConfig.Attributes, row),
+	}
+}
+
+// LookupBySearch resolves a value by searching for it and keeping the exact match
+func LookupBySearch(ctx context.Context, provider Searcher, dtConfig *config.DataTypeConfig, value string) (*models.DropdownItem, error) {
+	items, err := provider.Search(ctx, dtConfig, value)
+	if err != nil {
+		return nil, err
+	}
+	if item := database.FindItem(items, value); item != nil {
+		return item, nil
+	}
+	return nil, ErrNotFound
+}
+
+// Initialize connects every provider used by the given data types
+func Initialize(ctx context.Context, dataTypes []config.DataTypeConfig) error {
+	grouped := make(map[string][]config.DataTypeConfig)
+	var order []string
+	for _, dt := range dataTypes {
+		name := NameFor(&dt)
+		if _, seen := grouped[name]; !seen {
+			order = append(order, name)
+		}
+		grouped[name] = append(grouped[name], dt)
+	}
+
+	var errs []error
+	for _, name := range order {
+		provider, err := Get(name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		log.Printf("Initializing %s provider for %d data type(s)", name, len(grouped[name]))
+		if err := provider.Connect(ctx, grouped[name]); err != nil {
+			errs = append(errs, fmt.Errorf("%s provider: %v", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// CloseAll closes every provider instance that has been created
+func CloseAll() error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	var errs []error
+	for name, provider := range instances {
+		if err := provider.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("%s provider: %v", name, err))
+		}
+		delete(instances, name)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,263 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/jsonpath"
+	"snowflake-dropdown-api/internal/models"
+	"snowflake-dropdown-api/internal/providers"
+)
+
+func init() {
+	providers.Register("rest", func() providers.DataProvider {
+		return &Provider{client: &http.Client{}}
+	})
+}
+
+// Provider proxies searches to an HTTP API and maps the JSON response
+// into dropdown items using the configured JSONPath expressions
+type Provider struct {
+	client *http.Client
+}
+
+// Connect has nothing to prepare; upstream APIs are called per request
+func (p *Provider) Connect(ctx context.Context, dataTypes []config.DataTypeConfig) error {
+	return nil
+}
+
+// Search calls the upstream API and maps its response
+func (p *Provider) Search(ctx context.Context, dtConfig *config.DataTypeConfig, searchTerm string) ([]models.DropdownItem, error) {
+	rc := dtConfig.REST
+	if rc == nil {
+		return nil, fmt.Errorf("rest settings missing for data type '%s'", dtConfig.ID)
+	}
+
+	body, err := providers.DoWithRetry(ctx, p.client, rc.Retries, providers.UpstreamTimeout(rc.TimeoutSeconds),
+		func(ctx context.Context) (*http.Request, error) {
+			return newRequest(ctx, dtConfig, searchTerm)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := MapItems(body, rc.Mapping, dtConfig.Attributes)
+	if err != nil {
+		return nil, &providers.UpstreamError{StatusCode: http.StatusOK, Err: err}
+	}
+
+	if maxResults := config.GetMaxResults(); len(items) > maxResults {
+		items = items[:maxResults]
+	}
+	return items, nil
+}
+
+// Lookup searches for the value and returns the exact match
+func (p *Provider) Lookup(ctx context.Context, dtConfig *config.DataTypeConfig, value string) (*models.DropdownItem, error) {
+	return providers.LookupBySearch(ctx, p, dtConfig, value)
+}
+
+// ValidateConfig checks the endpoint, auth and mapping settings
+func (p *Provider) ValidateConfig(dtConfig *config.DataTypeConfig) error {
+	rc := dtConfig.REST
+	if rc == nil || rc.BaseURL == "" {
+		return fmt.Errorf("rest.baseUrl is required")
+	}
+	if _, err := url.Parse(config.ResolveValue(rc.BaseURL)); err != nil {
+		return fmt.Errorf("rest.baseUrl: %v", err)
+	}
+
+	switch strings.ToUpper(rc.Method) {
+	case "", http.MethodGet, http.MethodPost:
+	default:
+		return fmt.Errorf("rest.method must be GET or POST")
+	}
+
+	if err := providers.ValidateAuth(rc.Auth); err != nil {
+		return err
+	}
+
+	if rc.Mapping.Value == "" || rc.Mapping.Label == "" {
+		return fmt.Errorf("rest.mapping.value and rest.mapping.label are required")
+	}
+	if rc.Mapping.Results == "" {
+		if len(dtConfig.Attributes) > 0 {
+			return fmt.Errorf("rest.mapping.results is required with attributes, so each attribute is read from its own result")
+		}
+		if _, err := jsonpath.Compile(rc.Mapping.Value); err != nil {
+			return err
+		}
+		_, err := jsonpath.Compile(rc.Mapping.Label)
+		return err
+	}
+
+	if _, err := jsonpath.Compile(rc.Mapping.Results); err != nil {
+		return err
+	}
+	if _, err := jsonpath.CompileField(rc.Mapping.Value); err != nil {
+		return err
+	}
+	if _, err := jsonpath.CompileField(rc.Mapping.Label); err != nil {
+		return err
+	}
+	for _, attribute := range dtConfig.Attributes {
+		if _, err := jsonpath.CompileField(attribute.ColumnName()); err != nil {
+			return fmt.Errorf("attribute %s: %v", attribute.Name, err)
+		}
+	}
+	return nil
+}
+
+// Close releases idle upstream connections
+func (p *Provider) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
+
+// newRequest builds the upstream request for a search term
+func newRequest(ctx context.Context, dtConfig *config.DataTypeConfig, searchTerm string) (*http.Request, error) {
+	rc := dtConfig.REST
+	endpoint := strings.TrimRight(config.ResolveValue(rc.BaseURL), "/")
+	if rc.Endpoint != "" {
+		endpoint += "/" + strings.TrimLeft(providers.ExpandPlaceholders(rc.Endpoint, dtConfig, searchTerm, url.PathEscape), "/")
+	}
+
+	method := strings.ToUpper(rc.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	var body io.Reader
+	if method == http.MethodPost {
+		params := make(map[string]interface{}, len(rc.Params))
+		for key, value := range rc.Params {
+			params[key] = providers.ExpandParam(value, dtConfig, searchTerm)
+		}
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		query := req.URL.Query()
+		for key, value := range rc.Params {
+			query.Set(key, jsonpath.ToString(providers.ExpandParam(value, dtConfig, searchTerm)))
+		}
+		req.URL.RawQuery = query.Encode()
+	}
+
+	req.Header.Set("Accept", "application/json")
+	for key, value := range rc.Headers {
+		req.Header.Set(key, config.ResolveValue(value))
+	}
+	if err := providers.ApplyAuth(req, rc.Auth); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// MapItems decodes a JSON response and maps it to items. With a results
+// path each result object is mapped on its own, reading each attribute from
+// the JSONPath its column names; otherwise the selected values and labels
+// are zipped.
+func MapItems(body []byte, mapping config.MappingConfig, attributes []config.AttributeConfig) ([]models.DropdownItem, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON response: %v", err)
+	}
+
+	if mapping.Results != "" {
+		return mapResults(doc, mapping, attributes)
+	}
+	if len(attributes) > 0 {
+		return nil, fmt.Errorf("mapping.results is required with attributes")
+	}
+
+	valuePath, err := jsonpath.Compile(mapping.Value)
+	if err != nil {
+		return nil, err
+	}
+	labelPath, err := jsonpath.Compile(mapping.Label)
+	if err != nil {
+		return nil, err
+	}
+
+	values := valuePath.Strings(doc)
+	labels := labelPath.Strings(doc)
+	if len(values) != len(labels) {
+		return nil, fmt.Errorf("mapping selected %d values but %d labels", len(values), len(labels))
+	}
+
+	items := make([]models.DropdownItem, 0, len(values))
+	for i := range values {
+		items = append(items, models.DropdownItem{Value: values[i], Label: labels[i]})
+	}
+	return items, nil
+}
+
+// mapResults maps each object the results path selects to an item, skipping
+// objects without a value and leaving out attributes they lack
+func mapResults(doc interface{}, mapping config.MappingConfig, attributes []config.AttributeConfig) ([]models.DropdownItem, error) {
+	resultPath, err := jsonpath.Compile(mapping.Results)
+	if err != nil {
+		return nil, err
+	}
+	valuePath, err := jsonpath.CompileField(mapping.Value)
+	if err != nil {
+		return nil, err
+	}
+	labelPath, err := jsonpath.CompileField(mapping.Label)
+	if err != nil {
+		return nil, err
+	}
+	attributePaths := make([]*jsonpath.Path, len(attributes))
+	for i, attribute := range attributes {
+		if attributePaths[i], err = jsonpath.CompileField(attribute.ColumnName()); err != nil {
+			return nil, err
+		}
+	}
+
+	results := resultPath.Get(doc)
+	items := make([]models.DropdownItem, 0, len(results))
+	for _, result := range results {
+		values := valuePath.Strings(result)
+		if len(values) == 0 {
+			continue
+		}
+
+		item := models.DropdownItem{Value: values[0]}
+		if labels := labelPath.Strings(result); len(labels) > 0 {
+			item.Label = labels[0]
+		}
+		for i, path := range attributePaths {
+			if found := path.Strings(result); len(found) > 0 && found[0] != "" {
+				if item.Attributes == nil {
+					item.Attributes = make(map[string]string, len(attributes))
+				}
+				item.Attributes[attributes[i].Name] = found[0]
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
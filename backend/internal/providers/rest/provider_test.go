@@ -0,0 +1,141 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/providers"
+)
+
+func locationsConfig(baseURL string) *config.DataTypeConfig {
+	return &config.DataTypeConfig{
+		ID:       "api-locations",
+		Provider: "rest",
+		REST: &config.RESTConfig{
+			BaseURL:  baseURL,
+			Endpoint: "/locations/search",
+			Auth:     &config.AuthConfig{Type: "bearer", Token: "env:REST_TEST_TOKEN"},
+			Params:   map[string]interface{}{"q": "{searchTerm}", "limit": 50},
+			Mapping:  config.MappingConfig{Value: "$.data[*].id", Label: "$.data[*].name"},
+			Retries:  2,
+		},
+	}
+}
+
+func TestSearchMapsResponse(t *testing.T) {
+	os.Setenv("REST_TEST_TOKEN", "secret")
+	defer os.Unsetenv("REST_TEST_TOKEN")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/locations/search" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if got := r.URL.Query().Get("q"); got != "sea" {
+			t.Errorf("q = %q, want sea", got)
+		}
+		if got := r.URL.Query().Get("limit"); got != "50" {
+			t.Errorf("limit = %q, want 50", got)
+		}
+		fmt.Fprint(w, `{"data": [{"id": 7, "name": "Seattle"}, {"id": "SEA2", "name": "Seaside"}]}`)
+	}))
+	defer server.Close()
+
+	dt := locationsConfig(server.URL)
+	p := &Provider{client: server.Client()}
+	if err := p.ValidateConfig(dt); err != nil {
+		t.Fatalf("ValidateConfig() error = %v", err)
+	}
+
+	items, err := p.Search(context.Background(), dt, "sea")
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(items) != 2 || items[0].Value != "7" || items[0].Label != "Seattle" || items[1].Value != "SEA2" {
+		t.Errorf("Search() = %v", items)
+	}
+}
+
+func TestSearchRetriesServerErrors(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			http.Error(w, "warming up", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"data": [{"id": "A", "name": "Alpha"}]}`)
+	}))
+	defer server.Close()
+
+	dt := locationsConfig(server.URL)
+	dt.REST.Auth = nil
+	p := &Provider{client: server.Client()}
+
+	items, err := p.Search(context.Background(), dt, "a")
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(items) != 1 || atomic.LoadInt32(&calls) != 3 {
+		t.Errorf("got %d items after %d calls, want 1 after 3", len(items), calls)
+	}
+}
+
+func TestSearchMapsUpstreamErrors(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		http.Error(w, "no such endpoint", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	dt := locationsConfig(server.URL)
+	dt.REST.Auth = nil
+	p := &Provider{client: server.Client()}
+
+	_, err := p.Search(context.Background(), dt, "a")
+
+	var upstreamErr *providers.UpstreamError
+	if !errors.As(err, &upstreamErr) || upstreamErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("Search() error = %v, want upstream 404", err)
+	}
+	if got := providers.HTTPStatusFor(err); got != http.StatusBadGateway {
+		t.Errorf("HTTPStatusFor() = %d, want 502", got)
+	}
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("client errors should not be retried, got %d calls", calls)
+	}
+}
+
+func TestMapItemsPerResult(t *testing.T) {
+	body := []byte(`{"data": [
+		{"id": "BER", "name": "Berlin", "owner": "Anna"},
+		{"id": "NYC", "name": "New York"},
+		{"id": "SEA", "name": "Seattle", "owner": "Sam"}
+	]}`)
+	mapping := config.MappingConfig{Results: "$.data[*]", Value: "id", Label: "$.name"}
+	attributes := []config.AttributeConfig{{Name: "owner"}}
+
+	items, err := MapItems(body, mapping, attributes)
+	if err != nil {
+		t.Fatalf("MapItems() error = %v", err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("MapItems() = %v, want 3 items", items)
+	}
+	if items[0].Attributes["owner"] != "Anna" || items[1].Attributes != nil || items[2].Attributes["owner"] != "Sam" {
+		t.Errorf("attributes = %v, %v, %v, want Anna, none and Sam", items[0].Attributes, items[1].Attributes, items[2].Attributes)
+	}
+
+	if _, err := MapItems(body, config.MappingConfig{Value: "$.data[*].id", Label: "$.data[*].name"}, attributes); err == nil {
+		t.Error("MapItems() mapped attributes without a results path")
+	}
+}
@@ -0,0 +1,97 @@
+package providers
+
+import (
+	"context"
+	"strings"
+
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/database"
+	"snowflake-dropdown-api/internal/models"
+)
+
+// Record is a row of a data type's full dataset: the dropdown item plus the
+// row's column values keyed by lower-cased column name
+type Record struct {
+	Item   models.DropdownItem
+	Fields map[string]string
+}
+
+// Snapshotter is implemented by providers that can load a data type's full
+// dataset, including columns beyond value and label
+type Snapshotter interface {
+	LoadAll(ctx context.Context, dtConfig *config.DataTypeConfig) ([]Record, error)
+}
+
+// LoadAll returns the full dataset for a data type. Providers that do not
+// implement Snapshotter are searched with an empty term.
+func LoadAll(ctx context.Context, dtConfig *config.DataTypeConfig) ([]Record, error) {
+	provider, err := ForDataType(dtConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if snapshotter, ok := provider.(Snapshotter); ok {
+		return snapshotter.LoadAll(ctx, dtConfig)
+	}
+
+	items, err := provider.Search(ctx, dtConfig, "")
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]Record, len(items))
+	for i, item := range items {
+		records[i] = Record{
+			Item:   item,
+			Fields: map[string]string{"value": item.Value, "label": item.Label},
+		}
+	}
+	return records, nil
+}
+
+// DatasetQuery returns the query and parameters loading a data type's full
+// dataset: the snapshot query, then the fuzzy candidate query, falling back
+// to the generated dataset query of structured data types. Validation
+// requires one of them in snapshot and fuzzy mode; otherwise the search
+// query with an empty term is used.
+func DatasetQuery(dtConfig *config.DataTypeConfig) (string, []interface{}) {
+	if dtConfig.Snapshot != nil && dtConfig.Snapshot.Query != "" {
+		return dtConfig.Snapshot.Query, nil
+	}
+	if dtConfig.Fuzzy != nil && dtConfig.Fuzzy.CandidateQuery != "" {
+		return database.BindContext(dtConfig, dtConfig.Fuzzy.CandidateQuery, nil)
+	}
+	if database.Structured(dtConfig) {
+		return database.BuildStructuredDatasetQuery(dtConfig)
+	}
+	return database.BuildSearchQuery(dtConfig, "")
+}
+
+// SearchValues returns a record's value, label and search field values,
+// upper-cased for case-insensitive matching
+func SearchValues(dtConfig *config.DataTypeConfig, record Record) []string {
+	fields := append([]string{"value", "label"}, dtConfig.SearchFields...)
+	values := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if value, exists := record.Fields[strings.ToLower(field)]; exists && value != "" {
+			values = append(values, strings.ToUpper(value))
+		}
+	}
+	return values
+}
+
+// RecordsFromRows converts query rows into records, taking the item from the
+// "value" and "label" columns and the data type's attribute columns
+func RecordsFromRows(dtConfig *config.DataTypeConfig, rows []map[string]string) []Record {
+	records := make([]Record, 0, len(rows))
+	for _, row := range rows {
+		value := strings.TrimSpace(row["value"])
+		if value == "" {
+			continue
+		}
+		item := ItemFromRow(dtConfig, row)
+		item.Value = value
+		records = append(records, Record{Item: item, Fields: row})
+	}
+	return records
+}
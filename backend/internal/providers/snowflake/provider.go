@@ -0,0 +1,193 @@
+package snowflake
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/database"
+	"snowflake-dropdown-api/internal/models"
+	"snowflake-dropdown-api/internal/providers"
+
+	_ "github.com/snowflakedb/gosnowflake"
+)
+
+func init() {
+	providers.Register("snowflake", func() providers.DataProvider {
+		return newProvider()
+	})
+}
+
+// Provider serves data types from Snowflake using gosnowflake
+type Provider struct {
+	providers.SQLProvider
+	db *sql.DB
+}
+
+// newProvider returns a provider with its query methods bound to its
+// connection
+func newProvider() *Provider {
+	p := &Provider{}
+	p.SQLProvider = providers.SQLProvider{DB: p.conn}
+	return p
+}
+
+// Connect establishes the Snowflake connection with retry logic
+func (p *Provider) Connect(ctx context.Context, dataTypes []config.DataTypeConfig) error {
+	if os.Getenv("TEST_MODE") == "true" {
+		log.Println("TEST_MODE enabled - skipping database connection")
+		return nil
+	}
+
+	log.Println("Initializing Snowflake connection...")
+	log.Println("Note: If using SSO, your browser will open for authentication")
+
+	maxRetries := 3
+	retryDelay := 5 * time.Second
+
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		db, err := sql.Open("snowflake", getConnectionString())
+		if err != nil {
+			return fmt.Errorf("failed to create database connection: %v", err)
+		}
+
+		// Test the connection
+		pingCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		err = db.PingContext(pingCtx)
+		cancel()
+
+		if err == nil {
+			p.db = db
+			log.Println("Successfully connected to Snowflake")
+			return nil
+		}
+
+		// Close the current connection before retry or return
+		db.Close()
+
+		// Check if it's an invalid token error (390195)
+		if strings.Contains(err.Error(), "390195") || strings.Contains(err.Error(), "invalid") && strings.Contains(err.Error(), "token") {
+			log.Printf("Authentication failed (attempt %d/%d): Invalid ID Token detected", attempt, maxRetries)
+
+			if attempt < maxRetries {
+				log.Printf("Retrying authentication in %v...", retryDelay)
+				time.Sleep(retryDelay)
+				continue
+			}
+		}
+
+		// For other errors, don't retry
+		return fmt.Errorf("failed to connect to Snowflake: %v", err)
+	}
+
+	return fmt.Errorf("failed to connect to Snowflake after %d attempts: invalid ID token", maxRetries)
+}
+
+// Query executes a raw parameterized query against Snowflake
+func (p *Provider) Query(ctx context.Context, query string, params []interface{}) ([]models.DropdownItem, error) {
+	if p.db == nil {
+		return nil, fmt.Errorf("snowflake connection not initialized")
+	}
+	return database.QueryItems(ctx, p.db, nil, query, params...)
+}
+
+// ValidateConfig checks the data type query and required environment variables
+func (p *Provider) ValidateConfig(dtConfig *config.DataTypeConfig) error {
+	if err := database.ValidateSearchQuery(dtConfig); err != nil {
+		return err
+	}
+	if err := database.ValidatePlaceholders(dtConfig); err != nil {
+		return err
+	}
+	if err := database.ValidateDatasetQuery(dtConfig); err != nil {
+		return err
+	}
+
+	required := []string{
+		"SNOWFLAKE_ACCOUNT",
+		"SNOWFLAKE_USER",
+		"SNOWFLAKE_DATABASE",
+		"SNOWFLAKE_SCHEMA",
+		"SNOWFLAKE_WAREHOUSE",
+		"SNOWFLAKE_ROLE",
+	}
+
+	// Only require password if not using SSO
+	if os.Getenv("SNOWFLAKE_AUTH_TYPE") != "externalbrowser" {
+		required = append(required, "SNOWFLAKE_PASSWORD")
+	}
+
+	for _, env := range required {
+		if os.Getenv(env) == "" {
+			return fmt.Errorf("missing required environment variable: %s", env)
+		}
+	}
+
+	return nil
+}
+
+// Close closes the database connection
+func (p *Provider) Close() error {
+	if p.db != nil {
+		return p.db.Close()
+	}
+	return nil
+}
+
+// conn returns the Snowflake connection, which serves every data type
+func (p *Provider) conn(dtConfig *config.DataTypeConfig) (*sql.DB, error) {
+	if p.db == nil {
+		return nil, fmt.Errorf("snowflake connection not initialized")
+	}
+	return p.db, nil
+}
+
+// getConnectionString builds the Snowflake connection string
+func getConnectionString() string {
+	account := os.Getenv("SNOWFLAKE_ACCOUNT")
+
+	// Check if using SSO authentication
+	if os.Getenv("SNOWFLAKE_AUTH_TYPE") == "externalbrowser" {
+		// For SSO/External Browser auth, no password needed
+		dsn := fmt.Sprintf("%s@%s/%s/%s?warehouse=%s&authenticator=externalbrowser",
+			os.Getenv("SNOWFLAKE_USER"),
+			account,
+			os.Getenv("SNOWFLAKE_DATABASE"),
+			os.Getenv("SNOWFLAKE_SCHEMA"),
+			os.Getenv("SNOWFLAKE_WAREHOUSE"),
+		)
+
+		if role := os.Getenv("SNOWFLAKE_ROLE"); role != "" {
+			dsn += "&role=" + role
+		}
+
+		log.Printf("Connecting to Snowflake using SSO (external browser) for account: %s", account)
+		return dsn
+	}
+
+	// Standard username/password authentication
+	password := url.QueryEscape(os.Getenv("SNOWFLAKE_PASSWORD"))
+
+	dsn := fmt.Sprintf("%s:%s@%s/%s/%s?warehouse=%s",
+		os.Getenv("SNOWFLAKE_USER"),
+		password,
+		account,
+		os.Getenv("SNOWFLAKE_DATABASE"),
+		os.Getenv("SNOWFLAKE_SCHEMA"),
+		os.Getenv("SNOWFLAKE_WAREHOUSE"),
+	)
+
+	if role := os.Getenv("SNOWFLAKE_ROLE"); role != "" {
+		dsn += "&role=" + role
+	}
+
+	log.Printf("Connecting to Snowflake account: %s", account)
+
+	return dsn
+}
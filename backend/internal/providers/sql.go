@@ -0,0 +1,133 @@
+package providers
+
+import (
+	"context"
+	"database/sql"
+
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/database"
+	"snowflake-dropdown-api/internal/models"
+)
+
+// SQLProvider implements the query methods shared by the SQL providers.
+// Drivers embed it and add Connect, ValidateConfig and Close.
+type SQLProvider struct {
+	// DB returns the connection serving a data type
+	DB func(dtConfig *config.DataTypeConfig) (*sql.DB, error)
+
+	// Bind rewrites the ? placeholders of a query for the driver and returns
+	// the query and placeholder count; nil leaves them as they are
+	Bind func(query string) (string, int)
+}
+
+// Search runs the data type's configured query
+func (s *SQLProvider) Search(ctx context.Context, dtConfig *config.DataTypeConfig, searchTerm string) ([]models.DropdownItem, error) {
+	db, err := s.DB(dtConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	query, params := database.BuildSearchQuery(dtConfig, searchTerm)
+	query, count := s.bind(query)
+	return database.QueryItems(ctx, db, dtConfig.Attributes, query, database.TrimParams(params, count)...)
+}
+
+// LoadAll runs the data type's dataset query and returns every row
+func (s *SQLProvider) LoadAll(ctx context.Context, dtConfig *config.DataTypeConfig) ([]Record, error) {
+	rows, err := s.rows(dtConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	query, params := DatasetQuery(dtConfig)
+	result, err := rows(ctx, query, params)
+	if err != nil {
+		return nil, err
+	}
+	return RecordsFromRows(dtConfig, result), nil
+}
+
+// Describe checks the data type's queries return the expected columns
+func (s *SQLProvider) Describe(ctx context.Context, dtConfig *config.DataTypeConfig) error {
+	db, err := s.DB(dtConfig)
+	if err != nil {
+		return err
+	}
+
+	return DescribeSQL(ctx, dtConfig, func(ctx context.Context, query string, params []interface{}) ([]string, error) {
+		query, count := s.bind(query)
+		return database.QueryColumns(ctx, db, query, database.TrimParams(params, count)...)
+	})
+}
+
+// SearchPage runs the keyset query for a page of a structured data type
+func (s *SQLProvider) SearchPage(ctx context.Context, dtConfig *config.DataTypeConfig, searchTerm string, page Page) ([]models.DropdownItem, *Cursor, error) {
+	rows, err := s.rows(dtConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return SearchPageSQL(ctx, dtConfig, searchTerm, page, rows)
+}
+
+// LookupStatus runs the data type's status query for a value
+func (s *SQLProvider) LookupStatus(ctx context.Context, dtConfig *config.DataTypeConfig, value string) (*models.DropdownItem, string, error) {
+	rows, err := s.rows(dtConfig)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return LookupStatusSQL(ctx, dtConfig, value, rows)
+}
+
+// LookupStatusMany runs the data type's status query for values in batches
+func (s *SQLProvider) LookupStatusMany(ctx context.Context, dtConfig *config.DataTypeConfig, values []string) (map[string]ItemStatus, error) {
+	rows, err := s.rows(dtConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return LookupStatusManySQL(ctx, dtConfig, values, rows)
+}
+
+// LookupMany looks values up in batches with the data type's lookup query
+func (s *SQLProvider) LookupMany(ctx context.Context, dtConfig *config.DataTypeConfig, values []string) (map[string]models.DropdownItem, error) {
+	rows, err := s.rows(dtConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return LookupManySQL(ctx, dtConfig, values, rows)
+}
+
+// Lookup runs the data type's lookup query, or searches for the value, and
+// returns the exact match
+func (s *SQLProvider) Lookup(ctx context.Context, dtConfig *config.DataTypeConfig, value string) (*models.DropdownItem, error) {
+	rows, err := s.rows(dtConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return LookupSQL(ctx, s, dtConfig, value, rows)
+}
+
+// bind rewrites a query's placeholders for the driver
+func (s *SQLProvider) bind(query string) (string, int) {
+	if s.Bind == nil {
+		return query, database.CountPlaceholders(query)
+	}
+	return s.Bind(query)
+}
+
+// rows returns a RowsFunc running queries on the data type's connection
+func (s *SQLProvider) rows(dtConfig *config.DataTypeConfig) (RowsFunc, error) {
+	db, err := s.DB(dtConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, query string, params []interface{}) ([]map[string]string, error) {
+		query, count := s.bind(query)
+		return database.QueryRows(ctx, db, query, database.TrimParams(params, count)...)
+	}, nil
+}
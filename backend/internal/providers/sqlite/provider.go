@@ -0,0 +1,100 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/database"
+	"snowflake-dropdown-api/internal/providers"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func init() {
+	providers.Register("sqlite", func() providers.DataProvider {
+		return newProvider()
+	})
+}
+
+// Provider serves data types from local SQLite database files, opened read-only
+type Provider struct {
+	providers.SQLProvider
+	pools *database.Pools
+}
+
+// newProvider returns a provider with its query methods bound to its pools
+func newProvider() *Provider {
+	p := &Provider{pools: database.NewPools("sqlite3")}
+	p.SQLProvider = providers.SQLProvider{DB: p.pool}
+	return p
+}
+
+// Connect opens and verifies each configured database file
+func (p *Provider) Connect(ctx context.Context, dataTypes []config.DataTypeConfig) error {
+	for i := range dataTypes {
+		dt := &dataTypes[i]
+
+		db, err := p.pool(dt)
+		if err != nil {
+			return fmt.Errorf("data type '%s': %v", dt.ID, err)
+		}
+		if err := db.PingContext(ctx); err != nil {
+			return fmt.Errorf("failed to open SQLite database for data type '%s': %v", dt.ID, err)
+		}
+	}
+
+	log.Println("Successfully opened SQLite databases")
+	return nil
+}
+
+// ValidateConfig checks the data type query and database file
+func (p *Provider) ValidateConfig(dtConfig *config.DataTypeConfig) error {
+	if err := database.ValidateSearchQuery(dtConfig); err != nil {
+		return err
+	}
+	if err := database.ValidatePlaceholders(dtConfig); err != nil {
+		return err
+	}
+	if err := database.ValidateDatasetQuery(dtConfig); err != nil {
+		return err
+	}
+
+	conn := dtConfig.Connection
+	if conn == nil || (conn.Path == "" && conn.DSN == "") {
+		return fmt.Errorf("connection.path is required")
+	}
+	if conn.Path != "" {
+		if _, err := os.Stat(config.ResolveValue(conn.Path)); err != nil {
+			return fmt.Errorf("database file: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// Close closes all open database files
+func (p *Provider) Close() error {
+	return p.pools.Close()
+}
+
+// pool returns the connection pool for a data type
+func (p *Provider) pool(dtConfig *config.DataTypeConfig) (*sql.DB, error) {
+	conn := dtConfig.Connection
+	if conn == nil {
+		return nil, fmt.Errorf("connection.path is required")
+	}
+	return p.pools.Get(ConnectionString(conn), conn)
+}
+
+// ConnectionString returns the SQLite DSN for the connection settings.
+// Database files are opened read-only unless a DSN is given explicitly.
+func ConnectionString(conn *config.ConnectionConfig) string {
+	if conn.DSN != "" {
+		return config.ResolveValue(conn.DSN)
+	}
+	return fmt.Sprintf("file:%s?mode=ro", config.ResolveValue(conn.Path))
+}
@@ -0,0 +1,219 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"snowflake-dropdown-api/internal/config"
+)
+
+const (
+	defaultUpstreamTimeout = 10 * time.Second
+	maxUpstreamBody        = 10 << 20 // 10 MiB
+)
+
+// UpstreamError describes a failure calling a remote API
+type UpstreamError struct {
+	StatusCode int // status returned by the upstream, 0 if no response
+	Timeout    bool
+	Err        error
+}
+
+func (e *UpstreamError) Error() string {
+	switch {
+	case e.Timeout:
+		return fmt.Sprintf("upstream timed out: %v", e.Err)
+	case e.StatusCode != 0:
+		return fmt.Sprintf("upstream returned %d: %v", e.StatusCode, e.Err)
+	default:
+		return fmt.Sprintf("upstream request failed: %v", e.Err)
+	}
+}
+
+func (e *UpstreamError) Unwrap() error {
+	return e.Err
+}
+
+// HTTPStatus returns the status the API should answer with for this failure
+func (e *UpstreamError) HTTPStatus() int {
+	switch {
+	case e.Timeout:
+		return http.StatusGatewayTimeout
+	case e.StatusCode == http.StatusTooManyRequests:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// HTTPStatusFor returns the status the API should answer with for a provider error
+func HTTPStatusFor(err error) int {
+	var upstreamErr *UpstreamError
+	if errors.As(err, &upstreamErr) {
+		return upstreamErr.HTTPStatus()
+	}
+	if errors.Is(err, ErrNoLookupQuery) {
+		return http.StatusNotImplemented
+	}
+	return http.StatusInternalServerError
+}
+
+// UpstreamTimeout converts a configured timeout in seconds to a duration
+func UpstreamTimeout(seconds int) time.Duration {
+	if seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return defaultUpstreamTimeout
+}
+
+// ApplyAuth adds the configured credentials to an upstream request
+func ApplyAuth(req *http.Request, auth *config.AuthConfig) error {
+	if auth == nil {
+		return nil
+	}
+
+	switch auth.Type {
+	case "", "none":
+	case "bearer":
+		req.Header.Set("Authorization", "Bearer "+config.ResolveValue(auth.Token))
+	case "basic":
+		req.SetBasicAuth(config.ResolveValue(auth.Username), config.ResolveValue(auth.Password))
+	case "header":
+		req.Header.Set(auth.Header, config.ResolveValue(auth.Token))
+	default:
+		return fmt.Errorf("unsupported auth type '%s'", auth.Type)
+	}
+	return nil
+}
+
+// ValidateAuth checks auth settings and that referenced credentials are set
+func ValidateAuth(auth *config.AuthConfig) error {
+	if auth == nil {
+		return nil
+	}
+
+	switch auth.Type {
+	case "", "none":
+		return nil
+	case "bearer":
+		if config.ResolveValue(auth.Token) == "" {
+			return fmt.Errorf("auth token %s is empty", auth.Token)
+		}
+	case "basic":
+		if config.ResolveValue(auth.Username) == "" {
+			return fmt.Errorf("auth username %s is empty", auth.Username)
+		}
+	case "header":
+		if auth.Header == "" {
+			return fmt.Errorf("auth header name is required")
+		}
+		if config.ResolveValue(auth.Token) == "" {
+			return fmt.Errorf("auth token %s is empty", auth.Token)
+		}
+	default:
+		return fmt.Errorf("unsupported auth type '%s'", auth.Type)
+	}
+	return nil
+}
+
+// ExpandPlaceholders replaces {searchTerm}, {maxResults} and the data type's
+// {name} context parameters in s, passing the search term and parameter
+// values through escape
+func ExpandPlaceholders(s string, dtConfig *config.DataTypeConfig, searchTerm string, escape func(string) string) string {
+	replacements := []string{
+		"{searchTerm}", escape(searchTerm),
+		"{maxResults}", strconv.Itoa(config.GetMaxResults()),
+	}
+	for _, param := range dtConfig.Params {
+		replacements = append(replacements, "{"+param.Name+"}", escape(dtConfig.ContextValues[param.Name]))
+	}
+	return strings.NewReplacer(replacements...).Replace(s)
+}
+
+// ExpandParam expands placeholders in a configured parameter value.
+// A value of exactly "{maxResults}" becomes a number.
+func ExpandParam(value interface{}, dtConfig *config.DataTypeConfig, searchTerm string) interface{} {
+	s, ok := value.(string)
+	if !ok {
+		return value
+	}
+	if s == "{maxResults}" {
+		return config.GetMaxResults()
+	}
+	return ExpandPlaceholders(s, dtConfig, searchTerm, func(v string) string { return v })
+}
+
+// DoWithRetry sends the request built by newRequest, retrying network errors,
+// 429 and 5xx responses with exponential backoff. It returns the response body
+// of the first successful attempt.
+func DoWithRetry(ctx context.Context, client *http.Client, retries int, timeout time.Duration, newRequest func(ctx context.Context) (*http.Request, error)) ([]byte, error) {
+	backoff := 200 * time.Millisecond
+
+	var lastErr error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, &UpstreamError{Timeout: true, Err: ctx.Err()}
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		body, retry, err := doOnce(ctx, client, timeout, newRequest)
+		if err == nil {
+			return body, nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+
+	return nil, lastErr
+}
+
+// doOnce performs a single attempt and reports whether it may be retried
+func doOnce(ctx context.Context, client *http.Client, timeout time.Duration, newRequest func(ctx context.Context) (*http.Request, error)) ([]byte, bool, error) {
+	attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := newRequest(attemptCtx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		timedOut := errors.Is(err, context.DeadlineExceeded)
+		// Give up if the caller's own context is finished
+		return nil, ctx.Err() == nil, &UpstreamError{Timeout: timedOut, Err: err}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxUpstreamBody))
+	if err != nil {
+		return nil, true, &UpstreamError{StatusCode: resp.StatusCode, Err: err}
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return nil, retry, &UpstreamError{StatusCode: resp.StatusCode, Err: fmt.Errorf("%s", truncate(string(body), 200))}
+	}
+
+	return body, false, nil
+}
+
+// truncate shortens upstream error bodies for logging
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
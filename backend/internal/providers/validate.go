@@ -0,0 +1,262 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/database"
+)
+
+// describeTimeout bounds the checks of one data type's queries
+const describeTimeout = 30 * time.Second
+
+// paramName matches valid context parameter and attribute names
+var paramName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// reservedParams are names used by search requests and query templates
+var reservedParams = []string{"q", "limit", "cursor", "search", "searchTerm", "maxResults"}
+
+// reservedAttributes are column names the item queries use themselves
+var reservedAttributes = []string{"value", "label", "sort_key", "status"}
+
+// QueryDescriber is implemented by providers that can check a data type's
+// queries against the database without reading any rows
+type QueryDescriber interface {
+	Describe(ctx context.Context, dtConfig *config.DataTypeConfig) error
+}
+
+// ColumnsFunc returns the lower-cased columns a query returns
+type ColumnsFunc func(ctx context.Context, query string, params []interface{}) ([]string, error)
+
+// ValidationError reports the data types that failed validation
+type ValidationError struct {
+	Failures map[string]error // keyed by data type ID
+}
+
+// DataTypes returns the IDs of the failed data types in order
+func (e *ValidationError) DataTypes() []string {
+	ids := make([]string, 0, len(e.Failures))
+	for id := range e.Failures {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d data type(s) failed validation:", len(e.Failures))
+	for _, id := range e.DataTypes() {
+		fmt.Fprintf(&b, "\n  %s: %v", id, e.Failures[id])
+	}
+	return b.String()
+}
+
+// ValidateAll checks every data type against its provider. It returns a
+// *ValidationError listing the data types that failed.
+func ValidateAll(dataTypes []config.DataTypeConfig) error {
+	failures := make(map[string]error)
+	for i := range dataTypes {
+		dt := &dataTypes[i]
+		provider, err := ForDataType(dt)
+		if err != nil {
+			failures[dt.ID] = err
+			continue
+		}
+		if err := validateParams(dt); err != nil {
+			failures[dt.ID] = err
+			continue
+		}
+		if err := validateAttributes(dt); err != nil {
+			failures[dt.ID] = err
+			continue
+		}
+		if err := validateFuzzy(dt); err != nil {
+			failures[dt.ID] = err
+			continue
+		}
+		if err := provider.ValidateConfig(dt); err != nil {
+			failures[dt.ID] = err
+		}
+	}
+	return validationError(failures)
+}
+
+// validateParams checks the names, cascade behaviors and source data types
+// of a data type's context parameters
+func validateParams(dtConfig *config.DataTypeConfig) error {
+	seen := make(map[string]bool, len(dtConfig.Params))
+	for _, param := range dtConfig.Params {
+		if !paramName.MatchString(param.Name) || slices.Contains(reservedParams, param.Name) {
+			return fmt.Errorf("param name %q must be an identifier other than %s", param.Name, strings.Join(reservedParams, ", "))
+		}
+		if seen[param.Name] {
+			return fmt.Errorf("param %s is declared twice", param.Name)
+		}
+		seen[param.Name] = true
+
+		switch param.Cascade {
+		case "", config.CascadeClear, config.CascadeRefresh, config.CascadeDisable:
+		default:
+			return fmt.Errorf("param %s: cascade must be clear, refresh or disable", param.Name)
+		}
+		if param.DependsOn != "" && !declared(param.DependsOn) {
+			return fmt.Errorf("param %s depends on unknown data type '%s'", param.Name, param.DependsOn)
+		}
+	}
+	return nil
+}
+
+// validateAttributes checks the names and display styles of a data type's
+// attributes
+func validateAttributes(dtConfig *config.DataTypeConfig) error {
+	seen := make(map[string]bool, len(dtConfig.Attributes))
+	for _, attribute := range dtConfig.Attributes {
+		name := strings.ToLower(attribute.Name)
+		if !paramName.MatchString(attribute.Name) || slices.Contains(reservedAttributes, name) {
+			return fmt.Errorf("attribute name %q must be an identifier other than %s", attribute.Name, strings.Join(reservedAttributes, ", "))
+		}
+		if seen[name] {
+			return fmt.Errorf("attribute %s is declared twice", attribute.Name)
+		}
+		seen[name] = true
+
+		switch attribute.Display {
+		case "", config.DisplayText, config.DisplayBadge:
+		default:
+			return fmt.Errorf("attribute %s: display must be text or badge", attribute.Name)
+		}
+	}
+	return nil
+}
+
+// validateFuzzy checks that fuzzy mode ranks a data type's full dataset
+// rather than the first page of its search query: hand-written queries and
+// API providers need a candidate query or snapshot mode
+func validateFuzzy(dtConfig *config.DataTypeConfig) error {
+	if dtConfig.Fuzzy == nil || !dtConfig.Fuzzy.Enabled || dtConfig.Fuzzy.CandidateQuery != "" {
+		return nil
+	}
+	if dtConfig.Snapshot != nil && dtConfig.Snapshot.Enabled {
+		return nil
+	}
+	// Generated queries select the whole table and the file provider's
+	// LoadAll returns the whole file
+	if database.Structured(dtConfig) || dtConfig.File != nil {
+		return nil
+	}
+	return fmt.Errorf("fuzzy: candidateQuery or snapshot mode is required, the search query only returns the first results")
+}
+
+// declared reports whether a data type is configured, enabled or not
+func declared(id string) bool {
+	if config.AppConfig == nil {
+		return false
+	}
+	for _, dt := range config.AppConfig.DataTypes {
+		if dt.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// DescribeAll checks the queries of every data type whose provider
+// implements QueryDescriber against its connected database. It returns a
+// *ValidationError listing the data types that failed.
+func DescribeAll(ctx context.Context, dataTypes []config.DataTypeConfig) error {
+	failures := make(map[string]error)
+	for i := range dataTypes {
+		dt := &dataTypes[i]
+		provider, err := ForDataType(dt)
+		if err != nil {
+			failures[dt.ID] = err
+			continue
+		}
+		describer, ok := provider.(QueryDescriber)
+		if !ok {
+			continue
+		}
+
+		describeCtx, cancel := context.WithTimeout(ctx, describeTimeout)
+		err = describer.Describe(describeCtx, dt)
+		cancel()
+		if err != nil {
+			failures[dt.ID] = err
+		}
+	}
+	return validationError(failures)
+}
+
+// DescribeSQL checks that a data type's search and lookup queries start with
+// the value and label columns followed by its attributes, its dataset query
+// returns at least those, and its status query the value, label and status
+func DescribeSQL(ctx context.Context, dtConfig *config.DataTypeConfig, columns ColumnsFunc) error {
+	want := []string{"value", "label"}
+	for _, attribute := range dtConfig.Attributes {
+		want = append(want, strings.ToLower(attribute.Name))
+	}
+
+	query, params := database.BuildSearchQuery(dtConfig, "")
+	got, err := columns(ctx, query, params)
+	if err != nil {
+		return fmt.Errorf("search query: %v", err)
+	}
+	if !startsWith(got, want) {
+		return fmt.Errorf("search query returns columns (%s), want them to start with (%s)", strings.Join(got, ", "), strings.Join(want, ", "))
+	}
+
+	if lookup, params, ok := database.BuildLookupQuery(dtConfig, ""); ok {
+		if got, err = columns(ctx, lookup, params); err != nil {
+			return fmt.Errorf("lookup query: %v", err)
+		}
+		if !startsWith(got, want) {
+			return fmt.Errorf("lookup query returns columns (%s), want them to start with (%s)", strings.Join(got, ", "), strings.Join(want, ", "))
+		}
+	}
+
+	if dtConfig.Status != nil {
+		if got, err = columns(ctx, dtConfig.Status.Query, []interface{}{""}); err != nil {
+			return fmt.Errorf("status query: %v", err)
+		}
+		for _, column := range []string{"value", "label", "status"} {
+			if !slices.Contains(got, column) {
+				return fmt.Errorf("status query returns columns (%s), want value, label and status among them", strings.Join(got, ", "))
+			}
+		}
+	}
+
+	dataset, params := DatasetQuery(dtConfig)
+	if dataset == query {
+		return nil
+	}
+	if got, err = columns(ctx, dataset, params); err != nil {
+		return fmt.Errorf("dataset query: %v", err)
+	}
+	for _, column := range want {
+		if !slices.Contains(got, column) {
+			return fmt.Errorf("dataset query returns columns (%s), want %s among them", strings.Join(got, ", "), strings.Join(want, ", "))
+		}
+	}
+	return nil
+}
+
+// startsWith reports whether the columns begin with want; QueryItems ignores
+// any columns after them
+func startsWith(columns, want []string) bool {
+	return len(columns) >= len(want) && slices.Equal(columns[:len(want)], want)
+}
+
+// validationError returns the failures as a *ValidationError, or nil
+func validationError(failures map[string]error) error {
+	if len(failures) == 0 {
+		return nil
+	}
+	return &ValidationError{Failures: failures}
+}
@@ -0,0 +1,196 @@
+// Package scheduler runs each data type's cache warm-up or snapshot refresh
+// on its cron schedule. Runs are delayed by a random jitter and a job never
+// overlaps itself: the next run is only scheduled once the current one ends.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/search"
+	"snowflake-dropdown-api/internal/snapshot"
+
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	defaultJitter = 60 * time.Second
+	runTimeout    = 30 * time.Minute
+)
+
+// Job kinds
+const (
+	KindWarm     = "warm"
+	KindSnapshot = "snapshot"
+)
+
+// Status reports the state of a data type's scheduled job
+type Status struct {
+	DataType   string     `json:"dataType"`
+	Kind       string     `json:"kind"`
+	Schedule   string     `json:"schedule"`
+	Running    bool       `json:"running"`
+	NextRun    time.Time  `json:"nextRun"`
+	LastRun    *time.Time `json:"lastRun,omitempty"`
+	DurationMs int64      `json:"durationMs"`
+	Rows       int        `json:"rows"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// job is the scheduled work of one data type
+type job struct {
+	dtConfig config.DataTypeConfig
+	schedule cron.Schedule
+	jitter   time.Duration
+	status   Status
+}
+
+var (
+	mu   sync.Mutex
+	jobs = make(map[string]*job) // keyed by data type ID
+)
+
+// Start schedules a job for every data type with a schedule and runs them
+// until ctx is cancelled. Snapshot-mode data types have their snapshot
+// reloaded; others have their warm terms searched into the cache.
+func Start(ctx context.Context, dataTypes []config.DataTypeConfig) error {
+	scheduled := make([]*job, 0, len(dataTypes))
+	for _, dt := range dataTypes {
+		if dt.Schedule == nil || dt.Schedule.Cron == "" {
+			continue
+		}
+
+		schedule, err := cron.ParseStandard(dt.Schedule.Cron)
+		if err != nil {
+			return fmt.Errorf("data type %s: invalid schedule %q: %w", dt.ID, dt.Schedule.Cron, err)
+		}
+
+		j := &job{dtConfig: dt, schedule: schedule, jitter: Jitter(&dt)}
+		j.status = Status{DataType: dt.ID, Kind: KindWarm, Schedule: dt.Schedule.Cron}
+		if snapshot.Enabled(&dt) {
+			j.status.Kind = KindSnapshot
+		}
+		scheduled = append(scheduled, j)
+	}
+
+	mu.Lock()
+	for _, j := range scheduled {
+		jobs[j.dtConfig.ID] = j
+	}
+	mu.Unlock()
+
+	for _, j := range scheduled {
+		go j.loop(ctx, j.scheduleNext())
+	}
+	return nil
+}
+
+// Jitter returns the maximum random delay added to a data type's runs
+func Jitter(dtConfig *config.DataTypeConfig) time.Duration {
+	if dtConfig.Schedule != nil && dtConfig.Schedule.JitterSeconds > 0 {
+		return time.Duration(dtConfig.Schedule.JitterSeconds) * time.Second
+	}
+	if dtConfig.Schedule != nil && dtConfig.Schedule.JitterSeconds < 0 {
+		return 0
+	}
+	return defaultJitter
+}
+
+// Statuses returns the status of every scheduled job, ordered by data type
+func Statuses() []Status {
+	mu.Lock()
+	defer mu.Unlock()
+
+	statuses := make([]Status, 0, len(jobs))
+	for _, j := range jobs {
+		statuses = append(statuses, j.status)
+	}
+	sort.Slice(statuses, func(i, k int) bool { return statuses[i].DataType < statuses[k].DataType })
+	return statuses
+}
+
+// loop waits for each scheduled time and runs the job
+func (j *job) loop(ctx context.Context, next time.Time) {
+	for {
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		j.run(ctx)
+		next = j.scheduleNext()
+	}
+}
+
+// scheduleNext records and returns the job's next run time, including jitter
+func (j *job) scheduleNext() time.Time {
+	next := j.schedule.Next(time.Now())
+	if j.jitter > 0 {
+		next = next.Add(time.Duration(rand.Int63n(int64(j.jitter))))
+	}
+
+	mu.Lock()
+	j.status.NextRun = next
+	mu.Unlock()
+	return next
+}
+
+// run executes the job once and records its outcome
+func (j *job) run(ctx context.Context) {
+	start := time.Now()
+	lastRun := start.UTC()
+	mu.Lock()
+	j.status.Running = true
+	j.status.LastRun = &lastRun
+	mu.Unlock()
+
+	runCtx, cancel := context.WithTimeout(ctx, runTimeout)
+	rows, err := j.execute(runCtx)
+	cancel()
+
+	mu.Lock()
+	j.status.Running = false
+	j.status.DurationMs = time.Since(start).Milliseconds()
+	j.status.Rows = rows
+	j.status.Error = ""
+	if err != nil {
+		j.status.Error = err.Error()
+	}
+	mu.Unlock()
+
+	if err != nil {
+		log.Printf("Scheduled %s for %s failed: %v", j.status.Kind, j.dtConfig.ID, err)
+		return
+	}
+	log.Printf("Scheduled %s for %s loaded %d rows in %v", j.status.Kind, j.dtConfig.ID, rows, time.Since(start).Round(time.Millisecond))
+}
+
+// execute reloads the snapshot or warms the cache, returning the rows loaded
+func (j *job) execute(ctx context.Context) (int, error) {
+	if snapshot.Enabled(&j.dtConfig) {
+		if err := snapshot.Load(ctx, &j.dtConfig); err != nil {
+			return 0, err
+		}
+		return snapshot.Size(j.dtConfig.ID), nil
+	}
+
+	rows := 0
+	var errs []error
+	for _, result := range search.Warm(ctx, &j.dtConfig, search.WarmTerms(&j.dtConfig)) {
+		rows += result.Rows
+		if result.Error != "" {
+			errs = append(errs, fmt.Errorf("term %q: %s", result.Term, result.Error))
+		}
+	}
+	return rows, errors.Join(errs...)
+}
@@ -0,0 +1,173 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"snowflake-dropdown-api/internal/cache"
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/models"
+	"snowflake-dropdown-api/internal/providers"
+	"snowflake-dropdown-api/internal/snapshot"
+)
+
+// countingProvider returns two items per search, or fails for the term "fail"
+type countingProvider struct {
+	calls int32
+}
+
+func (p *countingProvider) Connect(ctx context.Context, dataTypes []config.DataTypeConfig) error {
+	return nil
+}
+
+func (p *countingProvider) Search(ctx context.Context, dtConfig *config.DataTypeConfig, searchTerm string) ([]models.DropdownItem, error) {
+	atomic.AddInt32(&p.calls, 1)
+	if searchTerm == "fail" {
+		return nil, errors.New("warehouse suspended")
+	}
+	return []models.DropdownItem{{Value: "1", Label: "One"}, {Value: "2", Label: "Two"}}, nil
+}
+
+func (p *countingProvider) Lookup(ctx context.Context, dtConfig *config.DataTypeConfig, value string) (*models.DropdownItem, error) {
+	return nil, providers.ErrNotFound
+}
+
+func (p *countingProvider) ValidateConfig(dtConfig *config.DataTypeConfig) error { return nil }
+
+func (p *countingProvider) Close() error { return nil }
+
+var testProvider = &countingProvider{}
+
+func init() {
+	providers.Register("scheduler-test", func() providers.DataProvider { return testProvider })
+}
+
+func status(t *testing.T, dataType string) Status {
+	t.Helper()
+	for _, s := range Statuses() {
+		if s.DataType == dataType {
+			return s
+		}
+	}
+	t.Fatalf("no job for %s", dataType)
+	return Status{}
+}
+
+func TestScheduledRuns(t *testing.T) {
+	config.AppConfig = &config.Config{}
+	config.AppConfig.CacheSettings = config.CacheSettings{Enabled: true, TTLMinutes: 60}
+	cache.Instance.Clear()
+	snapshot.Clear()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	schedule := &config.ScheduleConfig{Cron: "0 7 * * 1-5", JitterSeconds: -1}
+	dataTypes := []config.DataTypeConfig{
+		{ID: "warm-job", Provider: "scheduler-test", WarmPrefixes: []string{"a"}, Schedule: schedule},
+		{ID: "failing-job", Provider: "scheduler-test", WarmPrefixes: []string{"fail"}, Schedule: schedule},
+		{ID: "snapshot-job", Provider: "scheduler-test", Snapshot: &config.SnapshotConfig{Enabled: true}, Schedule: schedule},
+		{ID: "unscheduled", Provider: "scheduler-test"},
+	}
+	if err := Start(ctx, dataTypes); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	if s := status(t, "warm-job"); s.LastRun != nil || s.NextRun.Hour() != 7 || s.Kind != KindWarm {
+		t.Errorf("status before running = %+v, want next run at 07:00 and no last run", s)
+	}
+	for _, s := range Statuses() {
+		if s.DataType == "unscheduled" {
+			t.Error("data type without a schedule was scheduled")
+		}
+	}
+
+	// Run each job once instead of waiting for its schedule
+	mu.Lock()
+	scheduled := []*job{jobs["warm-job"], jobs["failing-job"], jobs["snapshot-job"]}
+	mu.Unlock()
+	for _, j := range scheduled {
+		j.run(ctx)
+	}
+
+	if s := status(t, "warm-job"); s.LastRun == nil || s.Rows != 4 || s.Error != "" || s.Running {
+		t.Errorf("warm status = %+v, want 4 rows from two terms", s)
+	}
+	if _, found := cache.Instance.Get("search:warm-job:a"); !found {
+		t.Error("warm-up did not cache the prefix search")
+	}
+	if s := status(t, "failing-job"); !strings.Contains(s.Error, "warehouse suspended") || s.Rows != 2 {
+		t.Errorf("failing status = %+v, want the query error", s)
+	}
+	if s := status(t, "snapshot-job"); s.Kind != KindSnapshot || s.Rows != 2 || snapshot.Size("snapshot-job") != 2 {
+		t.Errorf("snapshot status = %+v, want 2 snapshot rows", s)
+	}
+}
+
+func TestStartRejectsInvalidSchedule(t *testing.T) {
+	dataTypes := []config.DataTypeConfig{
+		{ID: "bad", Schedule: &config.ScheduleConfig{Cron: "every morning"}},
+	}
+	if err := Start(context.Background(), dataTypes); err == nil {
+		t.Error("Start() error = nil for an invalid cron expression")
+	}
+}
+
+func TestJitter(t *testing.T) {
+	tests := []struct {
+		schedule *config.ScheduleConfig
+		want     time.Duration
+	}{
+		{&config.ScheduleConfig{}, defaultJitter},
+		{&config.ScheduleConfig{JitterSeconds: 300}, 5 * time.Minute},
+		{&config.ScheduleConfig{JitterSeconds: -1}, 0},
+	}
+	for _, tt := range tests {
+		if got := Jitter(&config.DataTypeConfig{Schedule: tt.schedule}); got != tt.want {
+			t.Errorf("Jitter(%+v) = %v, want %v", tt.schedule, got, tt.want)
+		}
+	}
+}
+
+// slowProvider holds searches until release is closed
+type slowProvider struct {
+	countingProvider
+	release chan struct{}
+}
+
+func (p *slowProvider) Search(ctx context.Context, dtConfig *config.DataTypeConfig, searchTerm string) ([]models.DropdownItem, error) {
+	atomic.AddInt32(&p.calls, 1)
+	<-p.release
+	return []models.DropdownItem{{Value: "1", Label: "One"}}, nil
+}
+
+func TestSnapshotLoadsDoNotOverlap(t *testing.T) {
+	slow := &slowProvider{release: make(chan struct{})}
+	providers.Register("scheduler-slow", func() providers.DataProvider { return slow })
+	config.AppConfig = &config.Config{}
+	snapshot.Clear()
+
+	// A scheduled run while the snapshot is loading shares that load
+	dt := config.DataTypeConfig{ID: "slow-snapshot", Provider: "scheduler-slow", Snapshot: &config.SnapshotConfig{Enabled: true}}
+	done := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() { done <- snapshot.Load(context.Background(), &dt) }()
+	}
+	for atomic.LoadInt32(&slow.calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(slow.release)
+	for i := 0; i < 2; i++ {
+		if err := <-done; err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+	}
+	if calls := atomic.LoadInt32(&slow.calls); calls != 1 {
+		t.Errorf("provider loaded %d times, want 1", calls)
+	}
+}
@@ -0,0 +1,120 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"snowflake-dropdown-api/internal/cache"
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/models"
+	"snowflake-dropdown-api/internal/providers"
+	"snowflake-dropdown-api/internal/snapshot"
+)
+
+// CacheTypeStats describes the cached searches of a data type along with
+// the cache hits of its searches and item lookups
+type CacheTypeStats struct {
+	Entries          int   `json:"entries"`
+	Hits             int64 `json:"hits"`
+	Misses           int64 `json:"misses"`
+	LookupHits       int64 `json:"lookupHits"`
+	LookupMisses     int64 `json:"lookupMisses"`
+	OldestAgeSeconds int64 `json:"oldestAgeSeconds"`
+	NewestAgeSeconds int64 `json:"newestAgeSeconds"`
+}
+
+// CacheStats returns the cache entries and hit counters of each data type.
+// Entry ages are measured from when their results were fetched, and the
+// entries of a data type are read in one batch.
+func CacheStats(dataTypes []config.DataTypeConfig) map[string]CacheTypeStats {
+	counters := Stats()
+	now := time.Now()
+
+	result := make(map[string]CacheTypeStats, len(dataTypes))
+	for _, dt := range dataTypes {
+		s := CacheTypeStats{
+			Hits:         counters[dt.ID].CacheHits,
+			Misses:       counters[dt.ID].Requests,
+			LookupHits:   counters[dt.ID].LookupCacheHits,
+			LookupMisses: counters[dt.ID].LookupRequests,
+		}
+
+		var oldest, newest time.Duration
+		for _, response := range cache.Instance.Entries(CachePrefix(dt.ID)) {
+			s.Entries++
+
+			age := now.Sub(response.Metadata.ExportedAt)
+			if s.Entries == 1 || age > oldest {
+				oldest = age
+			}
+			if s.Entries == 1 || age < newest {
+				newest = age
+			}
+		}
+		s.OldestAgeSeconds = int64(oldest.Seconds())
+		s.NewestAgeSeconds = int64(newest.Seconds())
+
+		result[dt.ID] = s
+	}
+	return result
+}
+
+// WarmResult reports one search run to pre-populate the cache
+type WarmResult struct {
+	DataType   string `json:"dataType"`
+	Term       string `json:"term"`
+	Rows       int    `json:"rows"`
+	DurationMs int64  `json:"durationMs"`
+	Error      string `json:"error,omitempty"`
+}
+
+// WarmTerms returns the search terms a data type is warmed with: the empty
+// term followed by its configured warm prefixes
+func WarmTerms(dtConfig *config.DataTypeConfig) []string {
+	return append([]string{""}, dtConfig.WarmPrefixes...)
+}
+
+// Warm runs a data type's query for each term and caches the results,
+// replacing any cached entries for those terms
+func Warm(ctx context.Context, dtConfig *config.DataTypeConfig, terms []string) []WarmResult {
+	results := make([]WarmResult, 0, len(terms))
+	for _, term := range terms {
+		result := WarmResult{DataType: dtConfig.ID, Term: NormalizeTerm(term)}
+		start := time.Now()
+
+		rows, err := warmTerm(ctx, dtConfig, term)
+		result.Rows = rows
+		result.DurationMs = time.Since(start).Milliseconds()
+		if err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// warmTerm queries and caches the results for one search term
+func warmTerm(ctx context.Context, dtConfig *config.DataTypeConfig, searchTerm string) (int, error) {
+	if snapshot.Enabled(dtConfig) {
+		return 0, fmt.Errorf("data type %s is served from its snapshot", dtConfig.ID)
+	}
+	ttl, cacheEnabled := CacheTTL(dtConfig)
+	if !cacheEnabled {
+		return 0, fmt.Errorf("caching is disabled for data type %s", dtConfig.ID)
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	searchTerm = strings.TrimSpace(searchTerm)
+	key := CacheKey(dtConfig.ID, searchTerm)
+	result := <-flight.DoChan(key, func() (interface{}, error) {
+		return fetch(ctx, dtConfig, searchTerm, providers.Page{}, key, true, ttl+MaxStale())
+	})
+	if result.Err != nil {
+		return 0, result.Err
+	}
+	return len(result.Val.(models.DropdownResponse).Data), nil
+}
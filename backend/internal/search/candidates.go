@@ -0,0 +1,88 @@
+package search
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/models"
+	"snowflake-dropdown-api/internal/providers"
+)
+
+// candidateSet is the loaded fuzzy candidates of a data type
+type candidateSet struct {
+	items    []models.DropdownItem
+	values   [][]string // upper-cased search field values per item
+	loadedAt time.Time
+}
+
+var (
+	candidateMu   sync.Mutex
+	candidateSets = make(map[string]*candidateSet) // keyed by candidateKey
+)
+
+// candidates returns the fuzzy candidates of a data type. They are loaded
+// once per cache TTL and context rather than on every uncached search term.
+func candidates(ctx context.Context, dtConfig *config.DataTypeConfig) (*candidateSet, error) {
+	key := candidateKey(dtConfig)
+	ttl, cacheEnabled := CacheTTL(dtConfig)
+
+	candidateMu.Lock()
+	set, found := candidateSets[key]
+	candidateMu.Unlock()
+	if found && cacheEnabled && time.Since(set.loadedAt) < ttl {
+		return set, nil
+	}
+
+	result, err, _ := flight.Do("fuzzy:"+key, func() (interface{}, error) {
+		records, err := providers.LoadAll(ctx, dtConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		set := &candidateSet{
+			items:    make([]models.DropdownItem, len(records)),
+			values:   make([][]string, len(records)),
+			loadedAt: time.Now(),
+		}
+		for i, record := range records {
+			set.items[i] = record.Item
+			set.values[i] = providers.SearchValues(dtConfig, record)
+		}
+		if cacheEnabled {
+			candidateMu.Lock()
+			candidateSets[key] = set
+			candidateMu.Unlock()
+		}
+		return set, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*candidateSet), nil
+}
+
+// candidateKey identifies the candidates of a data type and its context
+func candidateKey(dtConfig *config.DataTypeConfig) string {
+	return dtConfig.ID + contextSuffix(dtConfig)
+}
+
+// dropCandidates drops the loaded fuzzy candidates of a data type
+func dropCandidates(dataType string) {
+	candidateMu.Lock()
+	defer candidateMu.Unlock()
+	for key := range candidateSets {
+		if key == dataType || strings.HasPrefix(key, dataType+"#") {
+			delete(candidateSets, key)
+		}
+	}
+}
+
+// ClearCandidates drops the loaded fuzzy candidates of every data type
+func ClearCandidates() {
+	candidateMu.Lock()
+	candidateSets = make(map[string]*candidateSet)
+	candidateMu.Unlock()
+}
@@ -0,0 +1,232 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"snowflake-dropdown-api/internal/cache"
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/models"
+	"snowflake-dropdown-api/internal/providers"
+	"snowflake-dropdown-api/internal/snapshot"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// resolveConcurrency bounds the lookups run in parallel by Resolve for
+// providers that cannot look values up in batches
+const resolveConcurrency = 8
+
+// notFoundTTL bounds how long unknown values are cached, so newly created
+// items are found soon
+const notFoundTTL = time.Minute
+
+// Lookup returns the item with exactly the given value, or nil if there is
+// none. Snapshot-mode data types are answered from memory; other lookups,
+// including unknown values, are cached like searches.
+func Lookup(ctx context.Context, dtConfig *config.DataTypeConfig, value string) (*models.DropdownItem, error) {
+	if snapshot.Enabled(dtConfig) {
+		if item, _, ok := snapshot.Lookup(dtConfig, value); ok {
+			return item, nil
+		}
+	}
+
+	key := ItemKey(dtConfig.ID, value)
+	ttl, cacheEnabled := CacheTTL(dtConfig)
+	if cacheEnabled {
+		if response, found := cache.Instance.Get(key); found {
+			recordLookupHit(dtConfig.ID)
+			return firstItem(response), nil
+		}
+	}
+
+	recordLookupRequest(dtConfig.ID)
+	result, err, _ := flight.Do(key, func() (interface{}, error) {
+		return fetchItem(ctx, dtConfig, value, cacheEnabled, ttl)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return firstItem(result.(models.DropdownResponse)), nil
+}
+
+// Resolve looks up each distinct value, returning the items found in the
+// order of values and the values no item matched. Values missing from the
+// snapshot and cache are looked up in batches where the provider and data
+// type support it, otherwise one by one: hand-written SQL queries without a
+// lookup query are searched for value by value.
+func Resolve(ctx context.Context, dtConfig *config.DataTypeConfig, values []string) (models.ResolveResponse, error) {
+	var distinct []string
+	seen := make(map[string]bool, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			distinct = append(distinct, value)
+		}
+	}
+
+	provider, err := providers.ForDataType(dtConfig)
+	if err != nil {
+		return models.ResolveResponse{}, err
+	}
+
+	found := make(map[string]*models.DropdownItem, len(distinct))
+	if batcher, ok := provider.(providers.BatchLookuper); ok && providers.CanLookupMany(dtConfig) {
+		err = resolveBatch(ctx, dtConfig, batcher, distinct, found)
+	} else {
+		err = resolveEach(ctx, dtConfig, distinct, found)
+	}
+	if err != nil {
+		return models.ResolveResponse{}, err
+	}
+
+	response := models.ResolveResponse{Data: []models.DropdownItem{}, Unknown: []string{}}
+	for _, value := range distinct {
+		if item := found[value]; item != nil {
+			response.Data = append(response.Data, *item)
+		} else {
+			response.Unknown = append(response.Unknown, value)
+		}
+	}
+	return response, nil
+}
+
+// resolveBatch answers values from the snapshot or cache and looks the rest
+// up with one provider query per batch, caching the outcome of each
+func resolveBatch(ctx context.Context, dtConfig *config.DataTypeConfig, batcher providers.BatchLookuper, values []string, found map[string]*models.DropdownItem) error {
+	ttl, cacheEnabled := CacheTTL(dtConfig)
+	var missing []string
+	for _, value := range values {
+		if snapshot.Enabled(dtConfig) {
+			if item, _, ok := snapshot.Lookup(dtConfig, value); ok {
+				found[value] = item
+				continue
+			}
+		}
+		if cacheEnabled {
+			if response, hit := cache.Instance.Get(ItemKey(dtConfig.ID, value)); hit {
+				recordLookupHit(dtConfig.ID)
+				found[value] = firstItem(response)
+				continue
+			}
+		}
+		missing = append(missing, value)
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	recordLookupRequest(dtConfig.ID)
+	recordLookupQuery(dtConfig.ID)
+	queryCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sharedQueryTimeout)
+	defer cancel()
+
+	items, err := batcher.LookupMany(queryCtx, dtConfig, missing)
+	if err != nil {
+		return err
+	}
+	for _, value := range missing {
+		var item *models.DropdownItem
+		if match, ok := items[value]; ok {
+			item = &match
+		}
+		found[value] = item
+		if cacheEnabled {
+			storeItem(dtConfig, value, item, ttl)
+		}
+	}
+	return nil
+}
+
+// resolveEach looks values up one by one, a bounded number in parallel
+func resolveEach(ctx context.Context, dtConfig *config.DataTypeConfig, values []string, found map[string]*models.DropdownItem) error {
+	var mu sync.Mutex
+	g, groupCtx := errgroup.WithContext(ctx)
+	g.SetLimit(resolveConcurrency)
+	for _, value := range values {
+		value := value
+		g.Go(func() error {
+			item, err := Lookup(groupCtx, dtConfig, value)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			found[value] = item
+			mu.Unlock()
+			return nil
+		})
+	}
+	return g.Wait()
+}
+
+// storeItem caches the outcome of a lookup. Unknown values are cached for at
+// most notFoundTTL.
+func storeItem(dtConfig *config.DataTypeConfig, value string, item *models.DropdownItem, ttl time.Duration) models.DropdownResponse {
+	response := itemResponse(dtConfig, item)
+	if item == nil {
+		ttl = min(ttl, notFoundTTL)
+	}
+	cache.Instance.SetWithTTL(ItemKey(dtConfig.ID, value), response, ttl)
+	return response
+}
+
+// itemResponse wraps the outcome of a lookup for the cache, an empty
+// response recording that the value is unknown
+func itemResponse(dtConfig *config.DataTypeConfig, item *models.DropdownItem) models.DropdownResponse {
+	response := models.DropdownResponse{
+		Data:     []models.DropdownItem{},
+		Metadata: models.Metadata{ExportedAt: time.Now().UTC(), Source: dtConfig.ID},
+	}
+	if item != nil {
+		response.Data = append(response.Data, *item)
+	}
+	response.Metadata.RowCount = len(response.Data)
+	return response
+}
+
+// fetchItem looks the value up with the provider and caches the outcome
+func fetchItem(ctx context.Context, dtConfig *config.DataTypeConfig, value string, cacheEnabled bool, ttl time.Duration) (models.DropdownResponse, error) {
+	provider, err := providers.ForDataType(dtConfig)
+	if err != nil {
+		return models.DropdownResponse{}, err
+	}
+
+	recordLookupQuery(dtConfig.ID)
+	queryCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sharedQueryTimeout)
+	defer cancel()
+
+	item, err := provider.Lookup(queryCtx, dtConfig, value)
+	switch {
+	case errors.Is(err, providers.ErrNotFound):
+		item = nil
+	case err != nil:
+		return models.DropdownResponse{}, err
+	}
+
+	if cacheEnabled {
+		return storeItem(dtConfig, value, item, ttl), nil
+	}
+	return itemResponse(dtConfig, item), nil
+}
+
+// firstItem returns the item of a cached lookup, or nil for unknown values
+func firstItem(response models.DropdownResponse) *models.DropdownItem {
+	if len(response.Data) == 0 {
+		return nil
+	}
+	item := response.Data[0]
+	return &item
+}
+
+// ItemKey builds the cache key for looking up a data type's value. Values
+// are matched exactly, so unlike search terms they are not normalized.
+func ItemKey(dataType, value string) string {
+	return ItemPrefix(dataType) + value
+}
+
+// ItemPrefix returns the key prefix shared by all cached lookups of a data type
+func ItemPrefix(dataType string) string {
+	return "item:" + dataType + ":"
+}
@@ -0,0 +1,334 @@
+// Package search runs data type searches against their providers,
+// caching results according to the cache settings. Snapshot-mode data
+// types are served from their in-memory snapshot once it is loaded.
+package search
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"snowflake-dropdown-api/internal/cache"
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/fuzzy"
+	"snowflake-dropdown-api/internal/models"
+	"snowflake-dropdown-api/internal/providers"
+	"snowflake-dropdown-api/internal/snapshot"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// sharedQueryTimeout bounds a coalesced query once detached from its request
+const sharedQueryTimeout = 2 * time.Minute
+
+var flight singleflight.Group
+
+// Execute returns the first page of search results for a data type
+func Execute(ctx context.Context, dtConfig *config.DataTypeConfig, searchTerm string) (models.DropdownResponse, error) {
+	return ExecutePage(ctx, dtConfig, searchTerm, providers.Page{})
+}
+
+// ExecutePage returns a page of search results for a data type. Pages hold
+// at most the maximum result count, which a zero limit defaults to. Data
+// types paged with keyset pagination query and cache each page; the others
+// page through their cached results.
+func ExecutePage(ctx context.Context, dtConfig *config.DataTypeConfig, searchTerm string, page providers.Page) (models.DropdownResponse, error) {
+	searchTerm = strings.TrimSpace(searchTerm)
+	if page.Limit >= config.GetMaxResults() {
+		page.Limit = 0
+	}
+
+	if !keyset(dtConfig) {
+		if page.After != nil && len(page.After.Keys) > 0 {
+			return models.DropdownResponse{}, providers.ErrInvalidCursor
+		}
+		response, err := execute(ctx, dtConfig, searchTerm, providers.Page{})
+		if err != nil {
+			return response, err
+		}
+		return paginate(response, page), nil
+	}
+
+	if page.After != nil && (len(page.After.Keys) == 0 || page.After.Offset != 0) {
+		return models.DropdownResponse{}, providers.ErrInvalidCursor
+	}
+	return execute(ctx, dtConfig, searchTerm, page)
+}
+
+// execute returns the search results for a page, served from the cache when
+// a fresh entry exists. Within the staleness window an expired entry is
+// served, marked stale, while it is refreshed in the background or when the
+// provider query fails.
+func execute(ctx context.Context, dtConfig *config.DataTypeConfig, searchTerm string, page providers.Page) (models.DropdownResponse, error) {
+	// Snapshot-mode data types are answered from memory once loaded, unless
+	// context parameters narrow the search
+	if snapshot.Enabled(dtConfig) && !hasContext(dtConfig) {
+		if items, loadedAt, ok := snapshot.Search(dtConfig, searchTerm); ok {
+			return models.DropdownResponse{
+				Data: items,
+				Metadata: models.Metadata{
+					ExportedAt: loadedAt,
+					RowCount:   len(items),
+					Source:     dtConfig.ID,
+					Cached:     true,
+				},
+			}, nil
+		}
+	}
+
+	key := pageKey(dtConfig, searchTerm, page)
+	ttl, cacheEnabled := CacheTTL(dtConfig)
+	maxStale := MaxStale()
+
+	// Entries are kept for the staleness window after their TTL; whether an
+	// entry is still fresh is judged by when its results were fetched
+	var stale *models.DropdownResponse
+	if cacheEnabled {
+		if response, found := cache.Instance.Get(key); found {
+			response.Metadata.Cached = true
+			if maxStale == 0 || time.Since(response.Metadata.ExportedAt) < ttl {
+				recordCacheHit(dtConfig.ID)
+				return response, nil
+			}
+
+			response.Metadata.Stale = true
+			if staleWhileRevalidate() {
+				recordStale(dtConfig.ID)
+				go refresh(ctx, dtConfig, searchTerm, page, key, ttl+maxStale)
+				return response, nil
+			}
+			stale = &response
+		}
+	}
+
+	// Identical concurrent searches share a single upstream query
+	recordRequest(dtConfig.ID)
+	leader := false
+	result, err, _ := flight.Do(key, func() (interface{}, error) {
+		leader = true
+		return fetch(ctx, dtConfig, searchTerm, page, key, cacheEnabled, ttl+maxStale)
+	})
+	if !leader {
+		recordCoalesced(dtConfig.ID)
+	}
+	if err != nil {
+		// Fall back to the last known good results while the provider is failing
+		if stale != nil {
+			log.Printf("Serving stale results for %s after query error: %v", dtConfig.ID, err)
+			recordStale(dtConfig.ID)
+			return *stale, nil
+		}
+		return models.DropdownResponse{}, err
+	}
+
+	return result.(models.DropdownResponse), nil
+}
+
+// fetch queries the provider and caches successful results for storeTTL.
+// It runs detached from the leading request so that request's cancellation
+// does not fail the others sharing the query.
+func fetch(ctx context.Context, dtConfig *config.DataTypeConfig, searchTerm string, page providers.Page, key string, cacheEnabled bool, storeTTL time.Duration) (models.DropdownResponse, error) {
+	recordUpstreamQuery(dtConfig.ID)
+
+	queryCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sharedQueryTimeout)
+	defer cancel()
+
+	response, err := query(queryCtx, dtConfig, searchTerm, page)
+	if err == nil && cacheEnabled {
+		cache.Instance.SetWithTTL(key, response, storeTTL)
+	}
+	return response, err
+}
+
+// refresh re-runs a search in the background to replace a stale cache entry.
+// A failed refresh leaves the stale entry in place.
+func refresh(ctx context.Context, dtConfig *config.DataTypeConfig, searchTerm string, page providers.Page, key string, storeTTL time.Duration) {
+	result := <-flight.DoChan(key, func() (interface{}, error) {
+		return fetch(ctx, dtConfig, searchTerm, page, key, true, storeTTL)
+	})
+	if result.Err != nil {
+		log.Printf("Background refresh failed for %s: %v", dtConfig.ID, result.Err)
+	}
+}
+
+// query fetches fresh results from the data type's provider. Keyset-paged
+// data types fetch the requested page only.
+func query(ctx context.Context, dtConfig *config.DataTypeConfig, searchTerm string, page providers.Page) (models.DropdownResponse, error) {
+	provider, err := providers.ForDataType(dtConfig)
+	if err != nil {
+		return models.DropdownResponse{}, err
+	}
+
+	var items []models.DropdownItem
+	var next *providers.Cursor
+	switch pager, ok := provider.(providers.Pager); {
+	case fuzzy.Enabled(dtConfig):
+		items, err = fuzzySearch(ctx, dtConfig, searchTerm)
+	case ok && keyset(dtConfig):
+		if page.Limit == 0 {
+			page.Limit = config.GetMaxResults()
+		}
+		items, next, err = pager.SearchPage(ctx, dtConfig, searchTerm, page)
+	default:
+		items, err = provider.Search(ctx, dtConfig, searchTerm)
+	}
+	if err != nil {
+		return models.DropdownResponse{}, err
+	}
+
+	response := models.DropdownResponse{
+		Data: items,
+		Metadata: models.Metadata{
+			ExportedAt: time.Now().UTC(),
+			RowCount:   len(items),
+			Source:     dtConfig.ID,
+			Cached:     false,
+		},
+	}
+	if next != nil {
+		response.Metadata.NextCursor = next.Encode()
+		response.Metadata.HasMore = true
+	}
+	return response, nil
+}
+
+// hasContext reports whether any context parameter of a search has a value
+func hasContext(dtConfig *config.DataTypeConfig) bool {
+	for _, value := range dtConfig.ContextValues {
+		if value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// keyset reports whether a data type's searches are paged by its provider
+func keyset(dtConfig *config.DataTypeConfig) bool {
+	return !snapshot.Enabled(dtConfig) && !fuzzy.Enabled(dtConfig) && providers.Keyset(dtConfig)
+}
+
+// paginate returns a page of a full result list, using offset cursors. A
+// list of the maximum result count may have been cut by its query, so its
+// last page is marked truncated rather than pointing past the list.
+func paginate(response models.DropdownResponse, page providers.Page) models.DropdownResponse {
+	offset := 0
+	if page.After != nil {
+		offset = min(page.After.Offset, len(response.Data))
+	}
+	limit := page.Limit
+	if limit == 0 {
+		limit = config.GetMaxResults()
+	}
+
+	total := len(response.Data)
+	end := min(offset+limit, total)
+	response.Metadata.Truncated = end == total && total >= config.GetMaxResults()
+	response.Data = response.Data[offset:end]
+	response.Metadata.RowCount = len(response.Data)
+	response.Metadata.HasMore = end < total
+	response.Metadata.NextCursor = ""
+	if response.Metadata.HasMore {
+		response.Metadata.NextCursor = (&providers.Cursor{Offset: end}).Encode()
+	}
+	return response
+}
+
+// fuzzySearch ranks the data type's candidate rows by their similarity to
+// the search term. Snapshot-mode data types rank their snapshot instead once
+// it is loaded, see execute.
+func fuzzySearch(ctx context.Context, dtConfig *config.DataTypeConfig, searchTerm string) ([]models.DropdownItem, error) {
+	set, err := candidates(ctx, dtConfig)
+	if err != nil {
+		return nil, err
+	}
+	return fuzzy.Rank(searchTerm, set.items, set.values, fuzzy.MinScore(dtConfig), config.GetMaxResults()), nil
+}
+
+// CacheKey builds the cache key for a data type and search term.
+// Terms are matched case-insensitively, so they are normalized to lower case.
+func CacheKey(dataType, searchTerm string) string {
+	return CachePrefix(dataType) + NormalizeTerm(searchTerm)
+}
+
+// pageKey returns the cache key of a page of results, including the values
+// of context parameters. The first page of the default size without context
+// shares the key of CacheKey.
+func pageKey(dtConfig *config.DataTypeConfig, searchTerm string, page providers.Page) string {
+	key := CacheKey(dtConfig.ID, searchTerm) + contextSuffix(dtConfig)
+	if page.Limit == 0 && page.After == nil {
+		return key
+	}
+	key += "#page=" + strconv.Itoa(page.Limit)
+	if page.After != nil {
+		key += ":" + page.After.Encode()
+	}
+	return key
+}
+
+// contextSuffix encodes the context parameter values of a search as a key
+// suffix, empty without context
+func contextSuffix(dtConfig *config.DataTypeConfig) string {
+	if !hasContext(dtConfig) {
+		return ""
+	}
+	values := url.Values{}
+	for name, value := range dtConfig.ContextValues {
+		if value != "" {
+			values.Set(name, value)
+		}
+	}
+	return "#" + values.Encode()
+}
+
+// CachePrefix returns the key prefix shared by all cached searches of a data type
+func CachePrefix(dataType string) string {
+	return "search:" + dataType + ":"
+}
+
+// InvalidateCache removes all cached searches, lookups and fuzzy candidates
+// for a data type
+func InvalidateCache(dataType string) {
+	cache.Instance.DeletePrefix(CachePrefix(dataType))
+	cache.Instance.DeletePrefix(ItemPrefix(dataType))
+	dropCandidates(dataType)
+}
+
+// NormalizeTerm trims and lower-cases a search term
+func NormalizeTerm(searchTerm string) string {
+	return strings.ToLower(strings.TrimSpace(searchTerm))
+}
+
+// CacheTTL returns how long results for a data type may be cached and
+// whether caching applies to it at all
+func CacheTTL(dtConfig *config.DataTypeConfig) (time.Duration, bool) {
+	appConfig := config.AppConfig
+	if appConfig == nil || !appConfig.CacheSettings.Enabled || dtConfig.CacheTTLMinutes < 0 {
+		return 0, false
+	}
+
+	if dtConfig.CacheTTLMinutes > 0 {
+		return time.Duration(dtConfig.CacheTTLMinutes) * time.Minute, true
+	}
+	if appConfig.CacheSettings.TTLMinutes > 0 {
+		return time.Duration(appConfig.CacheSettings.TTLMinutes) * time.Minute, true
+	}
+	return cache.Instance.Expiration(), true
+}
+
+// MaxStale returns how long after their TTL cached results may still be
+// served while they are refreshed or while the provider is failing
+func MaxStale() time.Duration {
+	if config.AppConfig == nil || config.AppConfig.CacheSettings.MaxStaleMinutes <= 0 {
+		return 0
+	}
+	return time.Duration(config.AppConfig.CacheSettings.MaxStaleMinutes) * time.Minute
+}
+
+// staleWhileRevalidate reports whether stale results are returned
+// immediately instead of waiting for a fresh query
+func staleWhileRevalidate() bool {
+	return config.AppConfig != nil && config.AppConfig.CacheSettings.StaleWhileRevalidate
+}
@@ -0,0 +1,199 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"snowflake-dropdown-api/internal/cache"
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/models"
+	"snowflake-dropdown-api/internal/providers"
+)
+
+// blockingProvider counts searches and holds them until release is closed
+type blockingProvider struct {
+	calls   int32
+	release chan struct{}
+}
+
+func (p *blockingProvider) Connect(ctx context.Context, dataTypes []config.DataTypeConfig) error {
+	return nil
+}
+
+func (p *blockingProvider) Search(ctx context.Context, dtConfig *config.DataTypeConfig, searchTerm string) ([]models.DropdownItem, error) {
+	atomic.AddInt32(&p.calls, 1)
+	<-p.release
+	return []models.DropdownItem{{Value: "1", Label: searchTerm}}, nil
+}
+
+func (p *blockingProvider) Lookup(ctx context.Context, dtConfig *config.DataTypeConfig, value string) (*models.DropdownItem, error) {
+	return nil, providers.ErrNotFound
+}
+
+func (p *blockingProvider) ValidateConfig(dtConfig *config.DataTypeConfig) error { return nil }
+
+func (p *blockingProvider) Close() error { return nil }
+
+var testProvider = &blockingProvider{release: make(chan struct{})}
+
+func init() {
+	providers.Register("blocking-test", func() providers.DataProvider { return testProvider })
+}
+
+func TestConcurrentSearchesAreCoalesced(t *testing.T) {
+	config.AppConfig = &config.Config{}
+	dt := &config.DataTypeConfig{ID: "coalesce-test", Provider: "blocking-test"}
+
+	const waiters = 10
+	var wg sync.WaitGroup
+	for i := 0; i < waiters; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := Execute(context.Background(), dt, "Mark"); err != nil {
+				t.Errorf("Execute() error = %v", err)
+			}
+		}()
+	}
+
+	// Wait for every request to arrive, then give them a moment to join the query
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		s := Stats()["coalesce-test"]
+		if s.Requests == waiters {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("only %d of %d requests arrived", s.Requests, waiters)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(testProvider.release)
+	wg.Wait()
+
+	s := Stats()["coalesce-test"]
+	if calls := atomic.LoadInt32(&testProvider.calls); calls != 1 {
+		t.Errorf("provider called %d times, want 1", calls)
+	}
+	if s.UpstreamQueries != 1 || s.Coalesced != waiters-1 {
+		t.Errorf("stats = %+v, want 1 upstream query and %d coalesced", s, waiters-1)
+	}
+}
+
+// flakyProvider returns one item per search, or err when it is set
+type flakyProvider struct {
+	mu    sync.Mutex
+	err   error
+	calls int
+}
+
+func (p *flakyProvider) Connect(ctx context.Context, dataTypes []config.DataTypeConfig) error {
+	return nil
+}
+
+func (p *flakyProvider) Search(ctx context.Context, dtConfig *config.DataTypeConfig, searchTerm string) ([]models.DropdownItem, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return []models.DropdownItem{{Value: "fresh", Label: searchTerm}}, nil
+}
+
+func (p *flakyProvider) Lookup(ctx context.Context, dtConfig *config.DataTypeConfig, value string) (*models.DropdownItem, error) {
+	return nil, providers.ErrNotFound
+}
+
+func (p *flakyProvider) ValidateConfig(dtConfig *config.DataTypeConfig) error { return nil }
+
+func (p *flakyProvider) Close() error { return nil }
+
+func (p *flakyProvider) set(err error) {
+	p.mu.Lock()
+	p.err = err
+	p.mu.Unlock()
+}
+
+var flaky = &flakyProvider{}
+
+func init() {
+	providers.Register("flaky-test", func() providers.DataProvider { return flaky })
+}
+
+// cacheExpired stores a response fetched two hours ago for a search
+func cacheExpired(dt *config.DataTypeConfig, searchTerm string) {
+	cache.Instance.SetWithTTL(CacheKey(dt.ID, searchTerm), models.DropdownResponse{
+		Data:     []models.DropdownItem{{Value: "old", Label: searchTerm}},
+		Metadata: models.Metadata{ExportedAt: time.Now().Add(-2 * time.Hour), RowCount: 1, Source: dt.ID},
+	}, time.Hour)
+}
+
+func TestStaleServedOnError(t *testing.T) {
+	config.AppConfig = &config.Config{}
+	config.AppConfig.CacheSettings = config.CacheSettings{Enabled: true, TTLMinutes: 60, MaxStaleMinutes: 180}
+	dt := &config.DataTypeConfig{ID: "stale-error-test", Provider: "flaky-test"}
+
+	flaky.set(errors.New("warehouse suspended"))
+	defer flaky.set(nil)
+	staleBefore := Stats()[dt.ID].Stale
+
+	if _, err := Execute(context.Background(), dt, "uncached"); err == nil {
+		t.Error("Execute() error = nil without a cached result")
+	}
+
+	cacheExpired(dt, "mark")
+	response, err := Execute(context.Background(), dt, "mark")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !response.Metadata.Stale || !response.Metadata.Cached || response.Data[0].Value != "old" {
+		t.Errorf("response = %+v, want the stale cached result", response)
+	}
+
+	// Once the provider recovers, the expired entry is replaced synchronously
+	flaky.set(nil)
+	response, err = Execute(context.Background(), dt, "mark")
+	if err != nil || response.Metadata.Stale || response.Data[0].Value != "fresh" {
+		t.Errorf("Execute() = %+v, %v, want fresh results", response, err)
+	}
+	if stale := Stats()[dt.ID].Stale - staleBefore; stale != 1 {
+		t.Errorf("stale count = %d, want 1", stale)
+	}
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	config.AppConfig = &config.Config{}
+	config.AppConfig.CacheSettings = config.CacheSettings{Enabled: true, TTLMinutes: 60, MaxStaleMinutes: 180, StaleWhileRevalidate: true}
+	dt := &config.DataTypeConfig{ID: "swr-test", Provider: "flaky-test"}
+
+	cacheExpired(dt, "mark")
+	response, err := Execute(context.Background(), dt, "mark")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !response.Metadata.Stale || response.Data[0].Value != "old" {
+		t.Errorf("response = %+v, want the stale cached result", response)
+	}
+
+	// The background refresh replaces the entry with fresh results
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		response, _ = Execute(context.Background(), dt, "mark")
+		if !response.Metadata.Stale {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("stale entry was not refreshed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if response.Data[0].Value != "fresh" || !response.Metadata.Cached {
+		t.Errorf("response = %+v, want cached fresh results", response)
+	}
+}
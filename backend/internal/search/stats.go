@@ -0,0 +1,99 @@
+package search
+
+import "sync"
+
+// TypeStats counts how searches and item lookups for a data type were served
+type TypeStats struct {
+	CacheHits       int64 `json:"cacheHits"`
+	Requests        int64 `json:"requests"`
+	UpstreamQueries int64 `json:"upstreamQueries"`
+	Coalesced       int64 `json:"coalesced"`
+	Stale           int64 `json:"stale"`
+	LookupCacheHits int64 `json:"lookupCacheHits"`
+	LookupRequests  int64 `json:"lookupRequests"`
+	LookupQueries   int64 `json:"lookupQueries"`
+}
+
+var (
+	statsMu sync.Mutex
+	stats   = make(map[string]*TypeStats)
+)
+
+// Stats returns a snapshot of the search and lookup counters per data type
+func Stats() map[string]TypeStats {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	snapshot := make(map[string]TypeStats, len(stats))
+	for dataType, s := range stats {
+		snapshot[dataType] = *s
+	}
+	return snapshot
+}
+
+// typeStats returns the counters for a data type; statsMu must be held
+func typeStats(dataType string) *TypeStats {
+	s, exists := stats[dataType]
+	if !exists {
+		s = &TypeStats{}
+		stats[dataType] = s
+	}
+	return s
+}
+
+// recordCacheHit counts a search answered with fresh cached results
+func recordCacheHit(dataType string) {
+	statsMu.Lock()
+	typeStats(dataType).CacheHits++
+	statsMu.Unlock()
+}
+
+// recordRequest counts a search that was not answered from the cache
+func recordRequest(dataType string) {
+	statsMu.Lock()
+	typeStats(dataType).Requests++
+	statsMu.Unlock()
+}
+
+// recordUpstreamQuery counts a query sent to the provider
+func recordUpstreamQuery(dataType string) {
+	statsMu.Lock()
+	typeStats(dataType).UpstreamQueries++
+	statsMu.Unlock()
+}
+
+// recordCoalesced counts a search that shared another request's query
+func recordCoalesced(dataType string) {
+	statsMu.Lock()
+	typeStats(dataType).Coalesced++
+	statsMu.Unlock()
+}
+
+// recordStale counts a search answered with stale cached results
+func recordStale(dataType string) {
+	statsMu.Lock()
+	typeStats(dataType).Stale++
+	statsMu.Unlock()
+}
+
+// recordLookupHit counts an item lookup answered from the cache
+func recordLookupHit(dataType string) {
+	statsMu.Lock()
+	typeStats(dataType).LookupCacheHits++
+	statsMu.Unlock()
+}
+
+// recordLookupRequest counts an item lookup that was not answered from the
+// cache
+func recordLookupRequest(dataType string) {
+	statsMu.Lock()
+	typeStats(dataType).LookupRequests++
+	statsMu.Unlock()
+}
+
+// recordLookupQuery counts a lookup query sent to the provider
+func recordLookupQuery(dataType string) {
+	statsMu.Lock()
+	typeStats(dataType).LookupQueries++
+	statsMu.Unlock()
+}
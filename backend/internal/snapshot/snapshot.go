@@ -0,0 +1,233 @@
+// Package snapshot holds the full dataset of data types configured for
+// snapshot mode in memory and answers searches from it. The provider is only
+// queried when a snapshot is loaded or refreshed.
+package snapshot
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/fuzzy"
+	"snowflake-dropdown-api/internal/models"
+	"snowflake-dropdown-api/internal/providers"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	defaultRefreshInterval = 60 * time.Minute
+	loadTimeout            = 5 * time.Minute
+)
+
+// set is the loaded dataset of one data type
+type set struct {
+	items    []models.DropdownItem
+	search   [][]string     // upper-cased search field values per item
+	byValue  map[string]int // index of each value's first item
+	loadedAt time.Time
+}
+
+var (
+	mu    sync.RWMutex
+	sets  = make(map[string]*set) // keyed by data type ID
+	loads singleflight.Group      // running loads, keyed by data type ID
+)
+
+// Enabled reports whether a data type is configured for snapshot mode
+func Enabled(dtConfig *config.DataTypeConfig) bool {
+	return dtConfig.Snapshot != nil && dtConfig.Snapshot.Enabled
+}
+
+// RefreshInterval returns how often a data type's snapshot is reloaded
+func RefreshInterval(dtConfig *config.DataTypeConfig) time.Duration {
+	if dtConfig.Snapshot != nil && dtConfig.Snapshot.RefreshMinutes > 0 {
+		return time.Duration(dtConfig.Snapshot.RefreshMinutes) * time.Minute
+	}
+	return defaultRefreshInterval
+}
+
+// Start loads the snapshot of every snapshot-mode data type and keeps
+// refreshing them until ctx is cancelled. Initial loads run in the
+// background; searches fall back to the provider until a snapshot is ready.
+// Data types with a schedule are only loaded once here, the scheduler owns
+// their refreshes.
+func Start(ctx context.Context, dataTypes []config.DataTypeConfig) {
+	for i := range dataTypes {
+		dt := dataTypes[i]
+		if !Enabled(&dt) {
+			continue
+		}
+		if dt.Schedule != nil && dt.Schedule.Cron != "" {
+			go func() {
+				if err := Load(ctx, &dt); err != nil {
+					log.Printf("Snapshot load failed for %s: %v", dt.ID, err)
+				}
+			}()
+			continue
+		}
+		go refreshLoop(ctx, &dt)
+	}
+}
+
+// refreshLoop loads a data type's snapshot immediately and then on its interval
+func refreshLoop(ctx context.Context, dtConfig *config.DataTypeConfig) {
+	ticker := time.NewTicker(RefreshInterval(dtConfig))
+	defer ticker.Stop()
+
+	for {
+		if err := Load(ctx, dtConfig); err != nil {
+			log.Printf("Snapshot refresh failed for %s: %v", dtConfig.ID, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Load fetches the full dataset for a data type and replaces its snapshot.
+// The previous snapshot is kept if loading fails. A call while the data
+// type's snapshot is loading waits for that load instead of starting another.
+func Load(ctx context.Context, dtConfig *config.DataTypeConfig) error {
+	_, err, _ := loads.Do(dtConfig.ID, func() (interface{}, error) {
+		return nil, load(ctx, dtConfig)
+	})
+	return err
+}
+
+// load runs one snapshot load
+func load(ctx context.Context, dtConfig *config.DataTypeConfig) error {
+	loadCtx, cancel := context.WithTimeout(ctx, loadTimeout)
+	defer cancel()
+
+	start := time.Now()
+	records, err := providers.LoadAll(loadCtx, dtConfig)
+	if err != nil {
+		return err
+	}
+
+	s := &set{
+		items:    make([]models.DropdownItem, len(records)),
+		search:   make([][]string, len(records)),
+		byValue:  make(map[string]int, len(records)),
+		loadedAt: time.Now().UTC(),
+	}
+	for i, record := range records {
+		s.items[i] = record.Item
+		s.search[i] = providers.SearchValues(dtConfig, record)
+		if _, exists := s.byValue[record.Item.Value]; !exists {
+			s.byValue[record.Item.Value] = i
+		}
+	}
+
+	mu.Lock()
+	sets[dtConfig.ID] = s
+	mu.Unlock()
+
+	log.Printf("Loaded snapshot of %d items for %s in %v", len(s.items), dtConfig.ID, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// Search returns the snapshot items matching a search term and when the
+// snapshot was loaded, ranked by relevance in fuzzy mode. ok is false if no
+// snapshot is loaded for the data type.
+func Search(dtConfig *config.DataTypeConfig, searchTerm string) (items []models.DropdownItem, loadedAt time.Time, ok bool) {
+	mu.RLock()
+	s, exists := sets[dtConfig.ID]
+	mu.RUnlock()
+	if !exists {
+		return nil, time.Time{}, false
+	}
+
+	maxResults := config.GetMaxResults()
+	if fuzzy.Enabled(dtConfig) {
+		return fuzzy.Rank(searchTerm, s.items, s.search, fuzzy.MinScore(dtConfig), maxResults), s.loadedAt, true
+	}
+
+	term := strings.ToUpper(strings.TrimSpace(searchTerm))
+	prefixOnly := dtConfig.Snapshot != nil && dtConfig.Snapshot.MatchMode == "prefix"
+
+	// Prefix matches are listed before other substring matches
+	var prefixed, contained []models.DropdownItem
+	for i, item := range s.items {
+		if len(prefixed) >= maxResults {
+			break
+		}
+		switch {
+		case term == "" || hasPrefix(s.search[i], term):
+			prefixed = append(prefixed, item)
+		case !prefixOnly && len(prefixed)+len(contained) < maxResults && contains(s.search[i], term):
+			contained = append(contained, item)
+		}
+	}
+
+	items = append(prefixed, contained...)
+	if len(items) > maxResults {
+		items = items[:maxResults]
+	}
+	return items, s.loadedAt, true
+}
+
+// Lookup returns the snapshot item with exactly the given value, or nil if
+// there is none. ok is false if no snapshot is loaded for the data type.
+func Lookup(dtConfig *config.DataTypeConfig, value string) (item *models.DropdownItem, loadedAt time.Time, ok bool) {
+	mu.RLock()
+	s, exists := sets[dtConfig.ID]
+	mu.RUnlock()
+	if !exists {
+		return nil, time.Time{}, false
+	}
+
+	if i, found := s.byValue[value]; found {
+		item := s.items[i]
+		return &item, s.loadedAt, true
+	}
+	return nil, s.loadedAt, true
+}
+
+// Size returns the number of items in a data type's loaded snapshot
+func Size(dataType string) int {
+	mu.RLock()
+	defer mu.RUnlock()
+	if s, exists := sets[dataType]; exists {
+		return len(s.items)
+	}
+	return 0
+}
+
+// Clear drops all loaded snapshots
+func Clear() {
+	mu.Lock()
+	sets = make(map[string]*set)
+	mu.Unlock()
+}
+
+// hasPrefix reports whether a value or one of its words starts with term
+func hasPrefix(values []string, term string) bool {
+	for _, value := range values {
+		if strings.HasPrefix(value, term) {
+			return true
+		}
+		for _, word := range strings.Fields(value) {
+			if strings.HasPrefix(word, term) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func contains(values []string, term string) bool {
+	for _, value := range values {
+		if strings.Contains(value, term) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,220 @@
+// Package validation checks stored values against their data type, telling
+// valid values from unknown and inactive ones. Values are always checked
+// with the provider rather than the cache, so results reflect current data.
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/models"
+	"snowflake-dropdown-api/internal/providers"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Value statuses
+const (
+	StatusValid    = "valid"
+	StatusInvalid  = "invalid"
+	StatusInactive = "inactive"
+)
+
+// checkConcurrency bounds the values checked in parallel by Check for
+// providers that cannot look values up in batches
+const checkConcurrency = 8
+
+// defaultActiveValues are the statuses of active items when a data type
+// does not list its own
+var defaultActiveValues = []string{"active", "true", "1", "y", "yes"}
+
+// Result is the outcome of checking one value
+type Result struct {
+	Value  string `json:"value"`
+	Status string `json:"status"`
+	Label  string `json:"label,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Valid reports whether the value exists and is active
+func (r Result) Valid() bool {
+	return r.Status == StatusValid
+}
+
+// Check checks each distinct value, returning the results in the order of
+// values. SQL data types are checked with one query per batch of values and
+// need a status query, lookup query or table; other providers check values
+// one by one.
+func Check(ctx context.Context, dtConfig *config.DataTypeConfig, values []string) ([]Result, error) {
+	var distinct []string
+	seen := make(map[string]bool, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			distinct = append(distinct, value)
+		}
+	}
+
+	provider, err := providers.ForDataType(dtConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err := Supported(dtConfig); err != nil {
+		return nil, err
+	}
+
+	var nonEmpty []string
+	for _, value := range distinct {
+		if strings.TrimSpace(value) != "" {
+			nonEmpty = append(nonEmpty, value)
+		}
+	}
+
+	var found map[string]providers.ItemStatus
+	hasStatus := dtConfig.Status != nil
+	checker, batchStatus := provider.(providers.BatchStatusChecker)
+	batcher, batch := provider.(providers.BatchLookuper)
+	switch {
+	case len(nonEmpty) == 0:
+	case hasStatus && batchStatus:
+		found, err = checker.LookupStatusMany(ctx, dtConfig, nonEmpty)
+	case batch:
+		found, hasStatus = make(map[string]providers.ItemStatus), false
+		var items map[string]models.DropdownItem
+		items, err = batcher.LookupMany(ctx, dtConfig, nonEmpty)
+		for value, item := range items {
+			found[value] = providers.ItemStatus{Item: item}
+		}
+	default:
+		return checkEach(ctx, dtConfig, distinct)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("data type '%s': %w", dtConfig.ID, err)
+	}
+
+	results := make([]Result, len(distinct))
+	for i, value := range distinct {
+		match, ok := found[value]
+		if !ok {
+			results[i] = result(dtConfig, value, nil, "", hasStatus)
+			continue
+		}
+		results[i] = result(dtConfig, value, &match.Item, match.Status, hasStatus)
+	}
+	return results, nil
+}
+
+// Supported checks that a data type's values can be validated: SQL data
+// types must not fall back to a search for the value, which only returns
+// the first results
+func Supported(dtConfig *config.DataTypeConfig) error {
+	provider, err := providers.ForDataType(dtConfig)
+	if err != nil {
+		return err
+	}
+	if _, batch := provider.(providers.BatchLookuper); !batch || dtConfig.Status != nil || providers.CanLookupMany(dtConfig) {
+		return nil
+	}
+	return fmt.Errorf("data type '%s': %w, which validation needs without a status query", dtConfig.ID, providers.ErrNoLookupQuery)
+}
+
+// checkEach checks values one by one, a bounded number in parallel
+func checkEach(ctx context.Context, dtConfig *config.DataTypeConfig, values []string) ([]Result, error) {
+	results := make([]Result, len(values))
+	g, groupCtx := errgroup.WithContext(ctx)
+	g.SetLimit(checkConcurrency)
+	for i, value := range values {
+		i, value := i, value
+		g.Go(func() error {
+			result, err := checkValue(groupCtx, dtConfig, value)
+			if err != nil {
+				return err
+			}
+			results[i] = result
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// CheckValue checks one value like Check
+func CheckValue(ctx context.Context, dtConfig *config.DataTypeConfig, value string) (Result, error) {
+	results, err := Check(ctx, dtConfig, []string{value})
+	if err != nil {
+		return Result{Value: value, Status: StatusInvalid}, err
+	}
+	return results[0], nil
+}
+
+// checkValue checks one value with the provider. Data types with a status
+// query are checked with it; others are valid when the provider's lookup
+// finds the value.
+func checkValue(ctx context.Context, dtConfig *config.DataTypeConfig, value string) (Result, error) {
+	if strings.TrimSpace(value) == "" {
+		return result(dtConfig, value, nil, "", false), nil
+	}
+
+	provider, err := providers.ForDataType(dtConfig)
+	if err != nil {
+		return Result{Value: value, Status: StatusInvalid}, err
+	}
+
+	var item *models.DropdownItem
+	status := ""
+	checker, hasStatus := provider.(providers.StatusChecker)
+	hasStatus = hasStatus && dtConfig.Status != nil
+	if hasStatus {
+		item, status, err = checker.LookupStatus(ctx, dtConfig, value)
+	} else {
+		item, err = provider.Lookup(ctx, dtConfig, value)
+	}
+	switch {
+	case errors.Is(err, providers.ErrNotFound):
+		item = nil
+	case err != nil:
+		return Result{Value: value, Status: StatusInvalid}, fmt.Errorf("data type '%s': %w", dtConfig.ID, err)
+	}
+	return result(dtConfig, value, item, status, hasStatus), nil
+}
+
+// result rates a value by the item found for it, nil if there is none, and
+// the item's status if it was looked up with the status query
+func result(dtConfig *config.DataTypeConfig, value string, item *models.DropdownItem, status string, hasStatus bool) Result {
+	result := Result{Value: value, Status: StatusInvalid}
+	switch {
+	case strings.TrimSpace(value) == "":
+		result.Reason = "value is empty"
+	case item == nil:
+		result.Reason = fmt.Sprintf("no %s item with value '%s'", dtConfig.ID, value)
+	case hasStatus && !Active(dtConfig, status):
+		result.Label = item.Label
+		result.Status = StatusInactive
+		result.Reason = fmt.Sprintf("%s item '%s' has status '%s'", dtConfig.ID, value, status)
+	default:
+		result.Label = item.Label
+		result.Status = StatusValid
+	}
+	return result
+}
+
+// Active reports whether a status marks an item of the data type as active
+func Active(dtConfig *config.DataTypeConfig, status string) bool {
+	activeValues := defaultActiveValues
+	if dtConfig.Status != nil && len(dtConfig.Status.ActiveValues) > 0 {
+		activeValues = dtConfig.Status.ActiveValues
+	}
+
+	status = strings.TrimSpace(status)
+	for _, active := range activeValues {
+		if strings.EqualFold(status, active) {
+			return true
+		}
+	}
+	return false
+}
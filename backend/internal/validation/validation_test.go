@@ -0,0 +1,30 @@
+package validation
+
+import (
+	"testing"
+
+	"snowflake-dropdown-api/internal/config"
+)
+
+func TestActive(t *testing.T) {
+	defaults := &config.DataTypeConfig{Status: &config.StatusConfig{}}
+	custom := &config.DataTypeConfig{Status: &config.StatusConfig{ActiveValues: []string{"Open", "Released"}}}
+
+	tests := []struct {
+		dt     *config.DataTypeConfig
+		status string
+		want   bool
+	}{
+		{defaults, "ACTIVE", true},
+		{defaults, " 1 ", true},
+		{defaults, "closed", false},
+		{defaults, "", false},
+		{custom, "released", true},
+		{custom, "active", false},
+	}
+	for _, tt := range tests {
+		if got := Active(tt.dt, tt.status); got != tt.want {
+			t.Errorf("Active(%v, %q) = %v, want %v", tt.dt.Status.ActiveValues, tt.status, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Package workitems checks the fields of Azure DevOps work items sent by
+// service hooks against their data types and records invalid values
+package workitems
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Service hook event types
+const (
+	EventCreated = "workitem.created"
+	EventUpdated = "workitem.updated"
+)
+
+// Event is a work item created or updated service hook event
+type Event struct {
+	Type         string
+	WorkItemID   int
+	Revision     int
+	Project      string
+	WorkItemType string
+	Fields       map[string]interface{} // field values after the change
+	Changed      map[string]bool        // fields changed by an update, nil for created work items
+}
+
+// payload is the part of a service hook request used here. Created events
+// carry the work item as the resource; updated events carry the update, with
+// the changed fields' old and new values and the work item revision.
+type payload struct {
+	EventType string `json:"eventType"`
+	Resource  struct {
+		ID         int                    `json:"id"`
+		WorkItemID int                    `json:"workItemId"`
+		Rev        int                    `json:"rev"`
+		Fields     map[string]interface{} `json:"fields"`
+		Revision   *struct {
+			ID     int                    `json:"id"`
+			Rev    int                    `json:"rev"`
+			Fields map[string]interface{} `json:"fields"`
+		} `json:"revision"`
+	} `json:"resource"`
+}
+
+// ParseEvent parses a service hook request body
+func ParseEvent(data []byte) (*Event, error) {
+	var p payload
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("invalid service hook payload: %v", err)
+	}
+
+	event := &Event{Type: p.EventType}
+	switch p.EventType {
+	case EventCreated:
+		event.WorkItemID = p.Resource.ID
+		event.Revision = p.Resource.Rev
+		event.Fields = p.Resource.Fields
+	case EventUpdated:
+		if p.Resource.Revision == nil {
+			return nil, fmt.Errorf("workitem.updated event without a revision")
+		}
+		event.WorkItemID = p.Resource.WorkItemID
+		if event.WorkItemID == 0 {
+			event.WorkItemID = p.Resource.Revision.ID
+		}
+		event.Revision = p.Resource.Revision.Rev
+		event.Fields = p.Resource.Revision.Fields
+		event.Changed = make(map[string]bool, len(p.Resource.Fields))
+		for field := range p.Resource.Fields {
+			event.Changed[field] = true
+		}
+	default:
+		return nil, fmt.Errorf("unsupported event type '%s'", p.EventType)
+	}
+
+	if event.WorkItemID == 0 {
+		return nil, fmt.Errorf("%s event without a work item id", p.EventType)
+	}
+	event.Project = FieldValue(event.Fields["System.TeamProject"])
+	event.WorkItemType = FieldValue(event.Fields["System.WorkItemType"])
+	return event, nil
+}
+
+// FieldValue returns a work item field value as a string, or "" if it is unset
+func FieldValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
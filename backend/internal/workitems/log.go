@@ -0,0 +1,41 @@
+package workitems
+
+import "sync"
+
+// maxViolations is the number of recent violations kept in memory
+const maxViolations = 500
+
+var (
+	violationsMu sync.Mutex
+	violations   []Violation // oldest first
+)
+
+// record appends violations to the log, dropping the oldest beyond maxViolations
+func record(found []Violation) {
+	violationsMu.Lock()
+	defer violationsMu.Unlock()
+
+	violations = append(violations, found...)
+	if excess := len(violations) - maxViolations; excess > 0 {
+		violations = append([]Violation(nil), violations[excess:]...)
+	}
+}
+
+// Violations returns the recorded violations, newest first
+func Violations() []Violation {
+	violationsMu.Lock()
+	defer violationsMu.Unlock()
+
+	result := make([]Violation, len(violations))
+	for i, v := range violations {
+		result[len(violations)-1-i] = v
+	}
+	return result
+}
+
+// ClearViolations empties the violation log
+func ClearViolations() {
+	violationsMu.Lock()
+	violations = nil
+	violationsMu.Unlock()
+}
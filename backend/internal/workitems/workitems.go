@@ -0,0 +1,201 @@
+package workitems
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"time"
+
+	"snowflake-dropdown-api/internal/ado"
+	"snowflake-dropdown-api/internal/config"
+	"snowflake-dropdown-api/internal/validation"
+)
+
+// Violation is an invalid or inactive value found in a work item field
+type Violation struct {
+	WorkItemID   int       `json:"workItemId"`
+	Revision     int       `json:"revision,omitempty"`
+	Project      string    `json:"project,omitempty"`
+	WorkItemType string    `json:"workItemType,omitempty"`
+	Field        string    `json:"field"`
+	DataType     string    `json:"dataType"`
+	Value        string    `json:"value"`
+	Status       string    `json:"status"`
+	Reason       string    `json:"reason"`
+	DetectedAt   time.Time `json:"detectedAt"`
+	ActionError  string    `json:"actionError,omitempty"` // set if commenting or tagging failed
+}
+
+// Report is the outcome of processing an event
+type Report struct {
+	EventType  string      `json:"eventType"`
+	WorkItemID int         `json:"workItemId"`
+	Checked    int         `json:"checked"`
+	Violations []Violation `json:"violations"`
+}
+
+// ValidateConfig checks that a secret is set, that field rules name enabled
+// data types whose values can be validated and that the ADO API is configured when violations are
+// commented on or tagged
+func ValidateConfig(hooks *config.ServiceHooks, adoConfig *config.ADOConfig) error {
+	if config.ResolveValue(hooks.Secret) == "" {
+		return fmt.Errorf("serviceHooks: secret is required")
+	}
+	for _, rule := range hooks.Fields {
+		if rule.Field == "" {
+			return fmt.Errorf("serviceHooks: field rule without a field")
+		}
+		dtConfig, err := config.GetDataTypeConfig(rule.DataType)
+		if err != nil {
+			return fmt.Errorf("serviceHooks: field %s: %v", rule.Field, err)
+		}
+		if err := validation.Supported(dtConfig); err != nil {
+			return fmt.Errorf("serviceHooks: field %s: %v", rule.Field, err)
+		}
+	}
+	if hooks.Comment || hooks.Tag != "" {
+		if _, err := ado.NewClient(adoConfig); err != nil {
+			return fmt.Errorf("serviceHooks: comment and tag need the ADO API: %v", err)
+		}
+	}
+	return nil
+}
+
+// Process checks the configured fields of a work item event. Created work
+// items are checked in full; for updates only changed fields are checked, so
+// tagging a work item does not check it again. Violations are recorded and,
+// if configured, commented on and tagged; failures to do so are logged and
+// recorded with the violations rather than returned.
+func Process(ctx context.Context, hooks *config.ServiceHooks, adoConfig *config.ADOConfig, event *Event) (*Report, error) {
+	report := &Report{EventType: event.Type, WorkItemID: event.WorkItemID, Violations: []Violation{}}
+
+	for _, rule := range hooks.Fields {
+		if !appliesTo(rule, event) {
+			continue
+		}
+		field, value := fieldValue(event.Fields, rule.Field)
+		if value == "" {
+			continue
+		}
+
+		dtConfig, err := config.GetDataTypeConfig(rule.DataType)
+		if err != nil {
+			log.Printf("Service hook: skipping field %s: %v", rule.Field, err)
+			continue
+		}
+		result, err := validation.CheckValue(ctx, dtConfig, value)
+		if err != nil {
+			return nil, err
+		}
+		report.Checked++
+		if result.Valid() {
+			continue
+		}
+
+		report.Violations = append(report.Violations, Violation{
+			WorkItemID:   event.WorkItemID,
+			Revision:     event.Revision,
+			Project:      event.Project,
+			WorkItemType: event.WorkItemType,
+			Field:        field,
+			DataType:     dtConfig.ID,
+			Value:        value,
+			Status:       result.Status,
+			Reason:       result.Reason,
+			DetectedAt:   time.Now().UTC(),
+		})
+	}
+
+	if len(report.Violations) > 0 {
+		if err := act(ctx, hooks, adoConfig, event, report.Violations); err != nil {
+			log.Printf("Service hook: updating work item %d: %v", event.WorkItemID, err)
+			for i := range report.Violations {
+				report.Violations[i].ActionError = err.Error()
+			}
+		}
+		record(report.Violations)
+	}
+	return report, nil
+}
+
+// appliesTo reports whether a field rule checks the event's work item
+func appliesTo(rule config.FieldRule, event *Event) bool {
+	if event.Changed != nil {
+		if field, _ := fieldValue(event.Changed, rule.Field); field == "" {
+			return false
+		}
+	}
+	if len(rule.WorkItemTypes) == 0 {
+		return true
+	}
+	for _, workItemType := range rule.WorkItemTypes {
+		if strings.EqualFold(workItemType, event.WorkItemType) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldValue finds a field by its case-insensitive reference name, returning
+// the name as sent and its value
+func fieldValue[V any](fields map[string]V, name string) (string, string) {
+	for field, value := range fields {
+		if strings.EqualFold(field, name) {
+			return field, FieldValue(value)
+		}
+	}
+	return "", ""
+}
+
+// act comments on and tags a work item with violations, as configured
+func act(ctx context.Context, hooks *config.ServiceHooks, adoConfig *config.ADOConfig, event *Event, violations []Violation) error {
+	if !hooks.Comment && hooks.Tag == "" {
+		return nil
+	}
+	client, err := ado.NewClient(adoConfig)
+	if err != nil {
+		return err
+	}
+
+	if hooks.Comment {
+		if err := client.AddComment(ctx, event.Project, event.WorkItemID, comment(violations)); err != nil {
+			return fmt.Errorf("adding comment: %w", err)
+		}
+	}
+	if tags, ok := addTag(FieldValue(event.Fields["System.Tags"]), hooks.Tag); ok {
+		if err := client.UpdateFields(ctx, event.WorkItemID, 0, map[string]string{"System.Tags": tags}); err != nil {
+			return fmt.Errorf("adding tag: %w", err)
+		}
+	}
+	return nil
+}
+
+// comment returns the HTML comment listing violations
+func comment(violations []Violation) string {
+	var b strings.Builder
+	b.WriteString("<p>Some field values are not valid:</p><ul>")
+	for _, v := range violations {
+		fmt.Fprintf(&b, "<li>%s: %s</li>", html.EscapeString(v.Field), html.EscapeString(v.Reason))
+	}
+	b.WriteString("</ul>")
+	return b.String()
+}
+
+// addTag returns the work item tags with tag added, and false if tag is
+// empty or already present
+func addTag(tags, tag string) (string, bool) {
+	if tag == "" {
+		return tags, false
+	}
+	for _, existing := range strings.Split(tags, ";") {
+		if strings.EqualFold(strings.TrimSpace(existing), tag) {
+			return tags, false
+		}
+	}
+	if strings.TrimSpace(tags) == "" {
+		return tag, true
+	}
+	return tags + "; " + tag, true
+}
@@ -0,0 +1,87 @@
+package workitems
+
+import (
+	"testing"
+
+	"snowflake-dropdown-api/internal/config"
+)
+
+func TestParseEvent(t *testing.T) {
+	created, err := ParseEvent([]byte(`{
+		"eventType": "workitem.created",
+		"resource": {"id": 42, "rev": 1, "fields": {
+			"System.TeamProject": "Finance", "System.WorkItemType": "Task", "Custom.CostCenter": "CC001"
+		}}
+	}`))
+	if err != nil {
+		t.Fatalf("created: %v", err)
+	}
+	if created.WorkItemID != 42 || created.Project != "Finance" || created.WorkItemType != "Task" || created.Changed != nil {
+		t.Errorf("created = %+v", created)
+	}
+
+	updated, err := ParseEvent([]byte(`{
+		"eventType": "workitem.updated",
+		"resource": {"id": 7, "workItemId": 42,
+			"fields": {"Custom.CostCenter": {"oldValue": "CC001", "newValue": "CC999"}},
+			"revision": {"id": 42, "rev": 3, "fields": {"System.WorkItemType": "Task", "Custom.CostCenter": "CC999", "Custom.Hours": 2.5}}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("updated: %v", err)
+	}
+	if updated.WorkItemID != 42 || updated.Revision != 3 || !updated.Changed["Custom.CostCenter"] || len(updated.Changed) != 1 {
+		t.Errorf("updated = %+v", updated)
+	}
+	if got := FieldValue(updated.Fields["Custom.Hours"]); got != "2.5" {
+		t.Errorf("FieldValue(2.5) = %q", got)
+	}
+
+	for _, body := range []string{
+		`{"eventType": "git.push", "resource": {}}`,
+		`{"eventType": "workitem.updated", "resource": {"workItemId": 42}}`,
+		`{"eventType": "workitem.created", "resource": {"fields": {}}}`,
+		`not json`,
+	} {
+		if _, err := ParseEvent([]byte(body)); err == nil {
+			t.Errorf("ParseEvent(%s) succeeded, want an error", body)
+		}
+	}
+}
+
+func TestAppliesTo(t *testing.T) {
+	rule := config.FieldRule{Field: "Custom.CostCenter", DataType: "cc", WorkItemTypes: []string{"Task", "Bug"}}
+
+	tests := []struct {
+		event *Event
+		want  bool
+	}{
+		{&Event{WorkItemType: "bug"}, true},
+		{&Event{WorkItemType: "Epic"}, false},
+		{&Event{WorkItemType: "Task", Changed: map[string]bool{"custom.costcenter": true}}, true},
+		{&Event{WorkItemType: "Task", Changed: map[string]bool{"System.Tags": true}}, false},
+	}
+	for _, tt := range tests {
+		if got := appliesTo(rule, tt.event); got != tt.want {
+			t.Errorf("appliesTo(%+v) = %v, want %v", tt.event, got, tt.want)
+		}
+	}
+}
+
+func TestAddTag(t *testing.T) {
+	tests := []struct {
+		tags, tag, want string
+		added           bool
+	}{
+		{"", "invalid-data", "invalid-data", true},
+		{"finance; q3", "invalid-data", "finance; q3; invalid-data", true},
+		{"finance; Invalid-Data", "invalid-data", "finance; Invalid-Data", false},
+		{"finance", "", "finance", false},
+	}
+	for _, tt := range tests {
+		got, added := addTag(tt.tags, tt.tag)
+		if got != tt.want || added != tt.added {
+			t.Errorf("addTag(%q, %q) = %q, %v, want %q, %v", tt.tags, tt.tag, got, added, tt.want, tt.added)
+		}
+	}
+}
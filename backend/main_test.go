@@ -1,131 +1,1147 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
-	"time"
+	"sync"
+	"testing"
 
+	adoclient "snowflake-dropdown-api/internal/ado"
+	"snowflake-dropdown-api/internal/api"
+	"snowflake-dropdown-api/internal/audit"
+	"snowflake-dropdown-api/internal/cache"
+	"snowflake-dropdown-api/internal/config"
 	"snowflake-dropdown-api/internal/models"
-
-	"github.com/gorilla/mux"
+	"snowflake-dropdown-api/internal/providers"
+	_ "snowflake-dropdown-api/internal/providers/file"
+	_ "snowflake-dropdown-api/internal/providers/sqlite"
+	searchpkg "snowflake-dropdown-api/internal/search"
+	"snowflake-dropdown-api/internal/snapshot"
+	"snowflake-dropdown-api/internal/workitems"
 )
 
-// Mock data for testing without Snowflake connection
+// Seed data for the SQLite test database
 var mockCCData = []models.DropdownItem{
-	{Value: "CC001", Label: "CC001 - Marketing SHOWBOAT"},
-	{Value: "CC002", Label: "CC002 - Engineering"},
-	{Value: "CC003", Label: "CC003 - Sales SHOWBOAT"},
-	{Value: "CC004", Label: "CC004 - Finance"},
-	{Value: "CC005", Label: "CC005 - HR Resources"},
+	{Value: "CC001", Label: "Marketing SHOWBOAT"},
+	{Value: "CC002", Label: "Engineering"},
+	{Value: "CC003", Label: "Sales SHOWBOAT"},
+	{Value: "CC004", Label: "Finance"},
+	{Value: "CC005", Label: "HR Resources"},
 }
 
 var mockWBSData = []models.DropdownItem{
-	{Value: "WBS001", Label: "WBS001 - Project BONSAI Alpha"},
-	{Value: "WBS002", Label: "WBS002 - Project Beta"},
-	{Value: "WBS003", Label: "WBS003 - BONSAI Development"},
-	{Value: "WBS004", Label: "WBS004 - Infrastructure"},
-	{Value: "WBS005", Label: "WBS005 - Research BONSAI"},
-}
-
-// Test mode handler
-func handleMockSearch(w http.ResponseWriter, r *http.Request) {
-	// Validate request method
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	vars := mux.Vars(r)
-	dataType := vars["type"]
-
-	// Validate dataType parameter
-	if dataType == "" {
-		http.Error(w, "Missing type parameter", http.StatusBadRequest)
-		return
-	}
-
-	// Sanitize and validate search term
-	searchTerm := strings.TrimSpace(r.URL.Query().Get("q"))
-	if len(searchTerm) > 100 { // Reasonable limit
-		http.Error(w, "Search term too long", http.StatusBadRequest)
-		return
-	}
-
-	var results []models.DropdownItem
-	var sourceData []models.DropdownItem
-
-	// Select source data based on type
-	switch strings.ToLower(dataType) {
-	case "cc":
-		sourceData = mockCCData
-	case "wbs":
-		sourceData = mockWBSData
-	default:
-		http.Error(w, fmt.Sprintf("Unknown type: %s", dataType), http.StatusBadRequest)
-		return
-	}
-
-	// Filter by search term
-	if searchTerm == "" {
-		results = sourceData
-	} else {
-		for _, item := range sourceData {
-			if containsIgnoreCase(item.Label, searchTerm) || containsIgnoreCase(item.Value, searchTerm) {
-				results = append(results, item)
+	{Value: "WBS001", Label: "Project BONSAI Alpha"},
+	{Value: "WBS002", Label: "Project Beta"},
+	{Value: "WBS003", Label: "BONSAI Development"},
+	{Value: "WBS004", Label: "Infrastructure"},
+	{Value: "WBS005", Label: "Research BONSAI"},
+}
+
+// createTestDatabase writes a SQLite database seeded with the mock data
+func createTestDatabase(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "reference.db")
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	defer db.Close()
+
+	tables := map[string][]models.DropdownItem{
+		"cost_centers": mockCCData,
+		"wbs_elements": mockWBSData,
+	}
+	for table, rows := range tables {
+		if _, err := db.Exec("CREATE TABLE " + table + " (code TEXT PRIMARY KEY, name TEXT NOT NULL)"); err != nil {
+			t.Fatalf("create %s: %v", table, err)
+		}
+		for _, row := range rows {
+			if _, err := db.Exec("INSERT INTO "+table+" (code, name) VALUES (?, ?)", row.Value, row.Label); err != nil {
+				t.Fatalf("insert into %s: %v", table, err)
 			}
 		}
 	}
 
-	// Return response
-	response := models.DropdownResponse{
-		Data: results,
-		Metadata: models.Metadata{
-			ExportedAt: time.Now().UTC(),
-			RowCount:   len(results),
-			Source:     dataType + "_mock",
-			Cached:     false,
+	return path
+}
+
+// setupTestServer configures SQLite-backed data types and returns the API router
+func setupTestServer(t *testing.T) http.Handler {
+	t.Helper()
+
+	conn := &config.ConnectionConfig{Path: createTestDatabase(t)}
+	config.AppConfig = &config.Config{
+		DataTypes: []config.DataTypeConfig{
+			{
+				ID:           "cc",
+				Name:         "Cost Centers",
+				Provider:     "sqlite",
+				Connection:   conn,
+				Query:        "SELECT code AS value, code || ' - ' || name AS label FROM cost_centers WHERE (? = '' OR UPPER(code) LIKE ? OR UPPER(name) LIKE ?) ORDER BY code",
+				SearchFields: []string{"code", "name"},
+				Enabled:      true,
+			},
+			{
+				ID:           "wbs",
+				Name:         "WBS Elements",
+				Provider:     "sqlite",
+				Connection:   conn,
+				Query:        "SELECT code AS value, code || ' - ' || name AS label FROM wbs_elements WHERE (? = '' OR UPPER(code) LIKE ? OR UPPER(name) LIKE ?) ORDER BY code",
+				SearchFields: []string{"code", "name"},
+				Enabled:      true,
+			},
 		},
+		DefaultDataType: "cc",
 	}
+	config.AppConfig.SearchSettings.MaxResults = 100
+	config.AppConfig.CacheSettings.Enabled = true
+	config.AppConfig.CacheSettings.TTLMinutes = 5
+	cache.Instance.Clear()
+	searchpkg.ClearCandidates()
+	snapshot.Clear()
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	if err := providers.ValidateAll(config.GetEnabledDataTypes()); err != nil {
+		t.Fatalf("validate providers: %v", err)
+	}
+	if err := providers.Initialize(context.Background(), config.GetEnabledDataTypes()); err != nil {
+		t.Fatalf("initialize providers: %v", err)
+	}
+	t.Cleanup(func() { providers.CloseAll() })
+
+	return api.SetupRouter()
+}
 
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-		return
+// search performs a search request and decodes the response
+func search(t *testing.T, handler http.Handler, path string) (int, models.DropdownResponse) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var response models.DropdownResponse
+	if rec.Code == http.StatusOK {
+		if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
 	}
+	return rec.Code, response
 }
 
-// Helper function for case-insensitive contains
-func containsIgnoreCase(s, substr string) bool {
-	return strings.Contains(strings.ToUpper(s), strings.ToUpper(substr))
+func values(items []models.DropdownItem) []string {
+	var result []string
+	for _, item := range items {
+		result = append(result, item.Value)
+	}
+	return result
 }
 
-// Add test mode to main function
-func setupTestMode(router *mux.Router) {
-	// Override search endpoint with mock data
-	router.HandleFunc("/api/search/{type}", handleMockSearch).Methods("GET")
+func TestSearchFiltersByTerm(t *testing.T) {
+	handler := setupTestServer(t)
 
-	// Add test data endpoint
-	router.HandleFunc("/api/test/data", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"/api/search/cc?q=showboat", []string{"CC001", "CC003"}},
+		{"/api/search/cc?q=cc004", []string{"CC004"}},
+		{"/api/search/wbs?q=bonsai", []string{"WBS001", "WBS003", "WBS005"}},
+		{"/api/search/wbs?q=nothing-matches", nil},
+	}
 
-		response := map[string]interface{}{
-			"cc_data":  mockCCData,
-			"wbs_data": mockWBSData,
-			"message":  "Test mode active - using mock data",
+	for _, tt := range tests {
+		code, response := search(t, handler, tt.path)
+		if code != http.StatusOK {
+			t.Fatalf("%s: status = %d, want 200", tt.path, code)
+		}
+		if got := values(response.Data); !equalStrings(got, tt.want) {
+			t.Errorf("%s: values = %v, want %v", tt.path, got, tt.want)
+		}
+		if response.Metadata.RowCount != len(tt.want) {
+			t.Errorf("%s: row_count = %d, want %d", tt.path, response.Metadata.RowCount, len(tt.want))
 		}
+	}
+}
+
+func TestSearchEmptyTermReturnsAll(t *testing.T) {
+	handler := setupTestServer(t)
+
+	_, response := search(t, handler, "/api/search/cc")
+	if len(response.Data) != len(mockCCData) {
+		t.Fatalf("got %d items, want %d", len(response.Data), len(mockCCData))
+	}
+	if got := response.Data[1].Label; got != "CC002 - Engineering" {
+		t.Errorf("label = %q, want %q", got, "CC002 - Engineering")
+	}
+}
+
+func TestSearchIsCached(t *testing.T) {
+	handler := setupTestServer(t)
+
+	_, first := search(t, handler, "/api/search/cc?q=showboat")
+	_, second := search(t, handler, "/api/search/cc?q=%20SHOWBOAT")
+	if first.Metadata.Cached || !second.Metadata.Cached {
+		t.Errorf("cached = %v then %v, want false then true", first.Metadata.Cached, second.Metadata.Cached)
+	}
+	if !equalStrings(values(second.Data), values(first.Data)) {
+		t.Errorf("cached values = %v, want %v", values(second.Data), values(first.Data))
+	}
+
+	// A negative per-type TTL disables caching for that type
+	config.AppConfig.DataTypes[1].CacheTTLMinutes = -1
+	search(t, handler, "/api/search/wbs?q=bonsai")
+	if _, again := search(t, handler, "/api/search/wbs?q=bonsai"); again.Metadata.Cached {
+		t.Error("wbs results were cached despite cacheTtlMinutes = -1")
+	}
+}
+
+func TestSnapshotServesFromMemory(t *testing.T) {
+	handler := setupTestServer(t)
+
+	dt := &config.AppConfig.DataTypes[0]
+	dt.Snapshot = &config.SnapshotConfig{Enabled: true}
+	if err := providers.ValidateAll(config.GetEnabledDataTypes()); err == nil || !strings.Contains(err.Error(), "snapshot.query") {
+		t.Errorf("ValidateAll() error = %v, want snapshot.query required", err)
+	}
+	dt.Snapshot.Query = "SELECT code AS value, code || ' - ' || name AS label, code, name FROM cost_centers ORDER BY code"
+	if err := snapshot.Load(context.Background(), dt); err != nil {
+		t.Fatalf("load snapshot: %v", err)
+	}
+
+	// Rows added after the snapshot was loaded are not visible until a refresh
+	db, err := sql.Open("sqlite3", dt.Connection.Path)
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("INSERT INTO cost_centers (code, name) VALUES ('CC006', 'Showboat Operations')"); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+
+	_, response := search(t, handler, "/api/search/cc?q=boat")
+	if want := []string{"CC001", "CC003"}; !equalStrings(values(response.Data), want) {
+		t.Errorf("values = %v, want %v", values(response.Data), want)
+	}
+	if !response.Metadata.Cached {
+		t.Error("snapshot results not reported as cached")
+	}
+
+	dt.Snapshot.MatchMode = "prefix"
+	if _, response := search(t, handler, "/api/search/cc?q=boat"); len(response.Data) != 0 {
+		t.Errorf("prefix mode matched %v", values(response.Data))
+	}
+	if _, response := search(t, handler, "/api/search/cc?q=res"); !equalStrings(values(response.Data), []string{"CC005"}) {
+		t.Errorf("prefix values = %v, want [CC005]", values(response.Data))
+	}
+
+	if err := snapshot.Load(context.Background(), dt); err != nil {
+		t.Fatalf("reload snapshot: %v", err)
+	}
+	if _, response := search(t, handler, "/api/search/cc?q=show"); !equalStrings(values(response.Data), []string{"CC001", "CC003", "CC006"}) {
+		t.Errorf("refreshed values = %v", values(response.Data))
+	}
+}
+
+func TestMultiTokenSearch(t *testing.T) {
+	handler := setupTestServer(t)
+	config.AppConfig.DataTypes[0].Query = "SELECT code AS value, code || ' - ' || name AS label FROM cost_centers WHERE {search} ORDER BY code"
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"showboat", []string{"CC001", "CC003"}},
+		{"showboat%20marketing", []string{"CC001"}},
+		{"marketing%20engineering", nil},
+		{"%22sales%20showboat%22", []string{"CC003"}},
+		{"%22showboat%20sales%22", nil},
+		{"", []string{"CC001", "CC002", "CC003", "CC004", "CC005"}},
+	}
+	for _, tt := range tests {
+		code, response := search(t, handler, "/api/search/cc?q="+tt.query)
+		if code != http.StatusOK {
+			t.Fatalf("q=%s: status %d", tt.query, code)
+		}
+		if got := values(response.Data); !equalStrings(got, tt.want) {
+			t.Errorf("q=%s: values = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestStructuredQuery(t *testing.T) {
+	handler := setupTestServer(t)
+	config.AppConfig.DataTypes[0] = config.DataTypeConfig{
+		ID:            "cc",
+		Name:          "Cost Centers",
+		Provider:      "sqlite",
+		Connection:    config.AppConfig.DataTypes[0].Connection,
+		Table:         "cost_centers",
+		ValueColumn:   "code",
+		LabelTemplate: "{name} ({code})",
+		SearchFields:  []string{"code", "name"},
+		OrderBy:       "code DESC",
+		Filters:       []config.FilterConfig{{Column: "code", Operator: "!=", Value: "CC003"}},
+		Enabled:       true,
+	}
+	if err := providers.ValidateAll(config.GetEnabledDataTypes()); err != nil {
+		t.Fatalf("validate providers: %v", err)
+	}
+
+	_, response := search(t, handler, "/api/search/cc?q=showboat")
+	if len(response.Data) != 1 || response.Data[0].Label != "Marketing SHOWBOAT (CC001)" {
+		t.Errorf("data = %+v, want CC001 labelled from the template", response.Data)
+	}
+
+	_, response = search(t, handler, "/api/search/cc")
+	if want := []string{"CC005", "CC004", "CC002", "CC001"}; !equalStrings(values(response.Data), want) {
+		t.Errorf("values = %v, want %v", values(response.Data), want)
+	}
+}
+
+func TestCursorPagination(t *testing.T) {
+	handler := setupTestServer(t)
+	config.AppConfig.DataTypes[0].Query = ""
+	config.AppConfig.DataTypes[0].Table = "cost_centers"
+	config.AppConfig.DataTypes[0].ValueColumn = "code"
+	config.AppConfig.DataTypes[0].LabelColumn = "name"
+
+	// pages follows next_cursor until has_more is false
+	pages := func(path string) [][]string {
+		var result [][]string
+		cursor := ""
+		for {
+			code, response := search(t, handler, path+"&cursor="+cursor)
+			if code != http.StatusOK {
+				t.Fatalf("%s: status %d", path, code)
+			}
+			result = append(result, values(response.Data))
+			if response.Metadata.HasMore != (response.Metadata.NextCursor != "") {
+				t.Fatalf("has_more = %v with next_cursor %q", response.Metadata.HasMore, response.Metadata.NextCursor)
+			}
+			if !response.Metadata.HasMore || len(result) > 5 {
+				return result
+			}
+			cursor = response.Metadata.NextCursor
+		}
+	}
 
-		if err := json.NewEncoder(w).Encode(response); err != nil {
-			http.Error(w, "Failed to encode test data response", http.StatusInternalServerError)
+	// Structured data types are paged with keyset conditions
+	got := pages("/api/search/cc?limit=2")
+	if want := [][]string{{"CC001", "CC002"}, {"CC003", "CC004"}, {"CC005"}}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("keyset pages = %v, want %v", got, want)
+	}
+
+	config.AppConfig.DataTypes[0].OrderBy = "name DESC"
+	cache.Instance.Clear()
+	got = pages("/api/search/cc?limit=3")
+	if want := [][]string{{"CC003", "CC001", "CC005"}, {"CC004", "CC002"}}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("pages by name = %v, want %v", got, want)
+	}
+
+	// Hand-written queries are paged through their cached results
+	got = pages("/api/search/wbs?q=bonsai&limit=2")
+	if want := [][]string{{"WBS001", "WBS003"}, {"WBS005"}}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("offset pages = %v, want %v", got, want)
+	}
+
+	// A result list of the maximum result count may have been cut by its
+	// query, so its last page is marked truncated
+	config.AppConfig.SearchSettings.MaxResults = 3
+	wbs := &config.AppConfig.DataTypes[1]
+	wbs.Query = strings.Replace(wbs.Query, "ORDER BY code", "ORDER BY code LIMIT 3", 1)
+	cache.Instance.Clear()
+	_, first := search(t, handler, "/api/search/wbs?limit=2")
+	_, last := search(t, handler, "/api/search/wbs?limit=2&cursor="+first.Metadata.NextCursor)
+	if first.Metadata.Truncated || !last.Metadata.Truncated || last.Metadata.HasMore || len(last.Data) != 1 {
+		t.Errorf("truncated pages = %+v then %+v, want the last page truncated", first.Metadata, last.Metadata)
+	}
+	if _, response := search(t, handler, "/api/search/cc?limit=2"); response.Metadata.Truncated || !response.Metadata.HasMore {
+		t.Errorf("keyset page = %+v, want more pages and no truncation", response.Metadata)
+	}
+	config.AppConfig.SearchSettings.MaxResults = 100
+
+	// Rows tying on the order column, or NULL in it, are neither skipped nor
+	// repeated: NULLs sort last and the value breaks ties
+	db, err := sql.Open("sqlite3", config.AppConfig.DataTypes[0].Connection.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{
+		"CREATE TABLE offices (code TEXT PRIMARY KEY, region TEXT)",
+		"INSERT INTO offices VALUES ('A1', 'EU'), ('A2', NULL), ('A3', 'EU'), ('A4', 'US'), ('A5', NULL), ('A6', 'EU')",
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	db.Close()
+	cc := &config.AppConfig.DataTypes[0]
+	cc.Table, cc.LabelColumn, cc.SearchFields = "offices", "code", []string{"code"}
+	for _, tt := range []struct {
+		orderBy string
+		want    [][]string
+	}{
+		{"region", [][]string{{"A1", "A3"}, {"A6", "A4"}, {"A2", "A5"}}},
+		{"region DESC", [][]string{{"A4", "A6"}, {"A3", "A1"}, {"A5", "A2"}}},
+		{"region, code DESC", [][]string{{"A6", "A3"}, {"A1", "A4"}, {"A5", "A2"}}},
+	} {
+		cc.OrderBy = tt.orderBy
+		cache.Instance.Clear()
+		if got := pages("/api/search/cc?limit=2"); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("pages ordered by %s = %v, want %v", tt.orderBy, got, tt.want)
+		}
+	}
+
+	for _, path := range []string{
+		"/api/search/cc?limit=0",
+		"/api/search/cc?cursor=not-a-cursor",
+		"/api/search/wbs?cursor=" + (&providers.Cursor{Keys: []*string{&wbs.ID}}).Encode(),
+		"/api/search/cc?cursor=" + (&providers.Cursor{Keys: []*string{nil}}).Encode(),
+	} {
+		if code, _ := search(t, handler, path); code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", path, code)
+		}
+	}
+}
+
+func TestItemLookup(t *testing.T) {
+	handler := setupTestServer(t)
+
+	// get performs a lookup and decodes the item
+	get := func(path string) (int, models.DropdownItem) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		var item models.DropdownItem
+		if rec.Code == http.StatusOK {
+			json.NewDecoder(rec.Body).Decode(&item)
+		}
+		return rec.Code, item
+	}
+
+	// Without a lookup query the value is searched for
+	if code, item := get("/api/items/cc/CC002"); code != http.StatusOK || item.Label != "CC002 - Engineering" {
+		t.Errorf("lookup = %d %+v, want CC002 - Engineering", code, item)
+	}
+
+	config.AppConfig.DataTypes[0].LookupQuery = "SELECT code AS value, name AS label FROM cost_centers WHERE code = ?"
+	if code, item := get("/api/items/cc/CC004"); code != http.StatusOK || item.Label != "Finance" {
+		t.Errorf("lookup = %d %+v, want Finance from the lookup query", code, item)
+	}
+	for _, path := range []string{"/api/items/cc/CC006", "/api/items/cc/cc004", "/api/items/cc/resolve"} {
+		if code, _ := get(path); code != http.StatusNotFound {
+			t.Errorf("%s: status %d, want 404", path, code)
+		}
+	}
+
+	// Unknown values are cached too
+	db, err := sql.Open("sqlite3", config.AppConfig.DataTypes[0].Connection.Path)
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("INSERT INTO cost_centers (code, name) VALUES ('CC006', 'Legal')"); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	if code, _ := get("/api/items/cc/CC006"); code != http.StatusNotFound {
+		t.Errorf("cached unknown value: status %d, want 404", code)
+	}
+
+	rec := httptest.NewRecorder()
+	body := `{"values": ["CC003", "CC999", "CC006", "CC003"]}`
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/items/cc/resolve", strings.NewReader(body)))
+	var resolved models.ResolveResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resolved); err != nil {
+		t.Fatalf("decode resolve response: %v (status %d)", err, rec.Code)
+	}
+	if got := values(resolved.Data); !equalStrings(got, []string{"CC003"}) || !equalStrings(resolved.Unknown, []string{"CC999", "CC006"}) {
+		t.Errorf("resolved = %+v, want CC003 with CC999 and CC006 unknown", resolved)
+	}
+
+	// Values missing from the cache are looked up with one query per batch
+	cache.Instance.Clear()
+	before := searchpkg.Stats()["cc"].LookupQueries
+	rec = httptest.NewRecorder()
+	body = `{"values": ["CC001", "CC002", "CC006", "CC998"]}`
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/items/cc/resolve", strings.NewReader(body)))
+	resolved = models.ResolveResponse{}
+	json.NewDecoder(rec.Body).Decode(&resolved)
+	if got := values(resolved.Data); !equalStrings(got, []string{"CC001", "CC002", "CC006"}) || !equalStrings(resolved.Unknown, []string{"CC998"}) {
+		t.Errorf("batch resolved = %+v, want CC001, CC002 and CC006 with CC998 unknown", resolved)
+	}
+	if queries := searchpkg.Stats()["cc"].LookupQueries - before; queries != 1 {
+		t.Errorf("batch resolve ran %d queries, want 1", queries)
+	}
+
+	// Hand-written queries without a lookup query are resolved value by value
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/items/wbs/resolve", strings.NewReader(`{"values": ["WBS001", "WBS999"]}`)))
+	resolved = models.ResolveResponse{}
+	if err := json.NewDecoder(rec.Body).Decode(&resolved); err != nil {
+		t.Fatalf("decode resolve response: %v (status %d)", err, rec.Code)
+	}
+	if got := values(resolved.Data); !equalStrings(got, []string{"WBS001"}) || !equalStrings(resolved.Unknown, []string{"WBS999"}) {
+		t.Errorf("resolve without lookupQuery = %+v, want WBS001 with WBS999 unknown", resolved)
+	}
+}
+
+func TestValueValidation(t *testing.T) {
+	handler := setupTestServer(t)
+
+	// validate posts values and decodes the response
+	validate := func(dataType, body string) (int, map[string]interface{}) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/validate/"+dataType, strings.NewReader(body)))
+		var response map[string]interface{}
+		if rec.Code == http.StatusOK {
+			json.NewDecoder(rec.Body).Decode(&response)
+		}
+		return rec.Code, response
+	}
+	statuses := func(response map[string]interface{}) []string {
+		var result []string
+		for _, r := range response["results"].([]interface{}) {
+			result = append(result, r.(map[string]interface{})["status"].(string))
+		}
+		return result
+	}
+
+	// Hand-written queries need a lookup query, a search for the value only
+	// returns the first results
+	if code, _ := validate("wbs", `{"values": ["WBS002"]}`); code != http.StatusNotImplemented {
+		t.Errorf("validation without lookupQuery: status %d, want 501", code)
+	}
+	config.AppConfig.DataTypes[1].LookupQuery = "SELECT code AS value, name AS label FROM wbs_elements WHERE code = ?"
+
+	// Without a status query found values are valid
+	_, response := validate("wbs", `{"values": ["WBS002", "WBS999", ""]}`)
+	if got := statuses(response); response["valid"] != false || !equalStrings(got, []string{"valid", "invalid", "invalid"}) {
+		t.Errorf("statuses = %v (valid %v), want valid, invalid, invalid", got, response["valid"])
+	}
+
+	config.AppConfig.DataTypes[0].Status = &config.StatusConfig{
+		Query: "SELECT code AS value, name AS label, CASE WHEN code = 'CC004' THEN 'Closed' ELSE 'Active' END AS status FROM cost_centers WHERE code = ?",
+	}
+	_, response = validate("cc", `{"values": ["CC001", "CC004", "CC999", "CC001"]}`)
+	if got := statuses(response); !equalStrings(got, []string{"valid", "inactive", "invalid"}) {
+		t.Errorf("statuses = %v, want valid, inactive, invalid", got)
+	}
+	inactive := response["results"].([]interface{})[1].(map[string]interface{})
+	if inactive["reason"] != "cc item 'CC004' has status 'Closed'" || inactive["label"] != "Finance" {
+		t.Errorf("inactive result = %v", inactive)
+	}
+
+	if _, response = validate("cc", `{"values": ["CC002"]}`); response["valid"] != true {
+		t.Errorf("valid = %v, want true", response["valid"])
+	}
+	if code, _ := validate("cc", `{"values": []}`); code != http.StatusBadRequest {
+		t.Errorf("empty values: status %d, want 400", code)
+	}
+}
+
+func TestWorkItemServiceHook(t *testing.T) {
+	handler := setupTestServer(t)
+	workitems.ClearViolations()
+	t.Setenv("ADMIN_API_KEY", "admin-secret")
+	config.AppConfig.DataTypes[0].LookupQuery = "SELECT code AS value, name AS label FROM cost_centers WHERE code = ?"
+
+	// Stub of the Azure DevOps REST API recording the requests it receives
+	var mu sync.Mutex
+	var calls []string
+	var bodies []string
+	ado := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if _, password, _ := r.BasicAuth(); password != "pat" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
 			return
 		}
-	}).Methods("GET")
+		mu.Lock()
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+		w.Write([]byte(`{}`))
+	}))
+	defer ado.Close()
+
+	config.AppConfig.ADO = &config.ADOConfig{
+		BaseURL: ado.URL + "/contoso",
+		Auth:    &config.AuthConfig{Type: "basic", Username: "pat", Password: "pat"},
+	}
+	config.AppConfig.ServiceHooks = &config.ServiceHooks{
+		Secret:  "hook-secret",
+		Fields:  []config.FieldRule{{Field: "Custom.CostCenter", DataType: "cc", WorkItemTypes: []string{"Task"}}},
+		Comment: true,
+		Tag:     "invalid-cost-center",
+	}
+	if err := workitems.ValidateConfig(config.AppConfig.ServiceHooks, config.AppConfig.ADO); err != nil {
+		t.Fatalf("ValidateConfig() error = %v", err)
+	}
+
+	// hook posts a service hook event and decodes the report
+	hook := func(secret, body string) (int, map[string]interface{}) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/hooks/workitems", strings.NewReader(body))
+		req.SetBasicAuth("", secret)
+		handler.ServeHTTP(rec, req)
+		var report map[string]interface{}
+		if rec.Code == http.StatusOK {
+			json.NewDecoder(rec.Body).Decode(&report)
+		}
+		return rec.Code, report
+	}
+	created := func(costCenter string) string {
+		return `{"eventType": "workitem.created", "resource": {"id": 42, "rev": 1, "fields": {
+			"System.TeamProject": "Finance", "System.WorkItemType": "Task", "System.Tags": "q3",
+			"Custom.CostCenter": "` + costCenter + `"}}}`
+	}
+
+	if code, _ := hook("wrong", created("CC001")); code != http.StatusUnauthorized {
+		t.Errorf("wrong secret: status %d, want 401", code)
+	}
+
+	code, report := hook("hook-secret", created("CC001"))
+	if code != http.StatusOK || report["checked"] != float64(1) || len(report["violations"].([]interface{})) != 0 {
+		t.Errorf("valid value: status %d, report %v", code, report)
+	}
+	if len(calls) != 0 {
+		t.Errorf("valid value called the ADO API: %v", calls)
+	}
+
+	_, report = hook("hook-secret", created("CC999"))
+	violations := report["violations"].([]interface{})
+	if len(violations) != 1 || violations[0].(map[string]interface{})["reason"] != "no cc item with value 'CC999'" {
+		t.Fatalf("violations = %v, want CC999", violations)
+	}
+	want := []string{"POST /contoso/Finance/_apis/wit/workItems/42/comments", "PATCH /contoso/_apis/wit/workitems/42"}
+	if !equalStrings(calls, want) {
+		t.Fatalf("ADO calls = %v, want %v", calls, want)
+	}
+	if !strings.Contains(bodies[0], "CC999") || !strings.Contains(bodies[1], `"value":"q3; invalid-cost-center"`) {
+		t.Errorf("ADO bodies = %v, want the comment and tags", bodies)
+	}
+
+	// Updates are checked only when a configured field changed, so the tag
+	// update above does not trigger another check
+	_, report = hook("hook-secret", `{"eventType": "workitem.updated", "resource": {"id": 3, "workItemId": 42,
+		"fields": {"System.Tags": {"oldValue": "q3", "newValue": "q3; invalid-cost-center"}},
+		"revision": {"id": 42, "rev": 2, "fields": {"System.WorkItemType": "Task", "Custom.CostCenter": "CC999"}}}}`)
+	if report["checked"] != float64(0) || len(calls) != 2 {
+		t.Errorf("tag update: report %v, calls %v", report, calls)
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/violations", nil)
+	req.Header.Set("X-Admin-Key", "admin-secret")
+	handler.ServeHTTP(rec, req)
+	var recorded []workitems.Violation
+	json.NewDecoder(rec.Body).Decode(&recorded)
+	if len(recorded) != 1 || recorded[0].WorkItemID != 42 || recorded[0].Value != "CC999" || recorded[0].ActionError != "" {
+		t.Errorf("recorded violations = %+v", recorded)
+	}
+
+	// The hook authenticates with its secret rather than the API key
+	t.Setenv("API_KEY", "api-key")
+	handler = api.SetupRouter()
+	if code, _ := hook("hook-secret", created("CC001")); code != http.StatusOK {
+		t.Errorf("hook with API_KEY set: status %d, want 200", code)
+	}
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/types", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("types without the API key: status %d, want 401", rec.Code)
+	}
+
+	// Without a secret the server refuses to start and requests are rejected
+	config.AppConfig.ServiceHooks.Secret = "env:UNSET_HOOK_SECRET"
+	if err := workitems.ValidateConfig(config.AppConfig.ServiceHooks, config.AppConfig.ADO); err == nil {
+		t.Error("ValidateConfig() accepted an empty secret")
+	}
+	if code, _ := hook("", created("CC999")); code != http.StatusUnauthorized {
+		t.Errorf("no secret configured: status %d, want 401", code)
+	}
+}
+
+func TestWorkItemAudit(t *testing.T) {
+	setupTestServer(t)
+	config.AppConfig.DataTypes[0].LookupQuery = "SELECT code AS value, name AS label FROM cost_centers WHERE code = ?"
+
+	// Mock of the Azure DevOps REST API. Work item 5 is changed by someone
+	// else after it is read, so rewriting it must fail.
+	type workItem struct {
+		rev   int
+		value string
+	}
+	items := map[int]*workItem{
+		1: {rev: 1, value: "CC001"},
+		2: {rev: 4, value: "marketing"},
+		3: {rev: 2, value: "CC999"},
+		4: {rev: 1, value: "eng"},
+		5: {rev: 1, value: "Marketing"},
+	}
+	var mu sync.Mutex
+	ado := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/org/Finance/_apis/wit/wiql":
+			fmt.Fprint(w, `{"workItems": [{"id": 1}, {"id": 2}, {"id": 3}, {"id": 4}, {"id": 5}, {"id": 6}]}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/org/Finance/_apis/wit/workitemsbatch":
+			var request struct{ IDs []int }
+			json.NewDecoder(r.Body).Decode(&request)
+			var value []interface{}
+			for _, id := range request.IDs {
+				item, ok := items[id]
+				if !ok {
+					value = append(value, nil)
+					continue
+				}
+				value = append(value, map[string]interface{}{"id": id, "rev": item.rev, "fields": map[string]interface{}{
+					"System.WorkItemType": "Task", "System.Title": fmt.Sprintf("Item %d", id), "Custom.CostCenter": item.value,
+				}})
+			}
+			items[5].rev++
+			json.NewEncoder(w).Encode(map[string]interface{}{"count": len(value), "value": value})
+		case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, "/org/_apis/wit/workitems/"):
+			var id int
+			fmt.Sscanf(strings.TrimPrefix(r.URL.Path, "/org/_apis/wit/workitems/"), "%d", &id)
+			var patch []struct {
+				Op    string
+				Path  string
+				Value interface{}
+			}
+			json.NewDecoder(r.Body).Decode(&patch)
+			item := items[id]
+			for _, op := range patch {
+				switch {
+				case op.Op == "test" && op.Path == "/rev" && op.Value != float64(item.rev):
+					http.Error(w, "work item changed", http.StatusPreconditionFailed)
+					return
+				case op.Op == "add" && op.Path == "/fields/Custom.CostCenter":
+					item.value = op.Value.(string)
+				}
+			}
+			item.rev++
+			w.Write([]byte(`{}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ado.Close()
+
+	client, err := adoclient.NewClient(&config.ADOConfig{BaseURL: ado.URL + "/org"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dtConfig, _ := config.GetDataTypeConfig("cc")
+	opts := audit.Options{
+		Project: "Finance",
+		Field:   "Custom.CostCenter",
+		Mapping: map[string]string{"MARKETING": "CC001", "CC999": "CC998"},
+	}
+	actions := func(report *audit.Report) []string {
+		var result []string
+		for _, row := range report.Rows {
+			result = append(result, fmt.Sprintf("%d:%s", row.WorkItemID, row.Action))
+		}
+		return result
+	}
+
+	// Without apply rewrites are only planned
+	report, err := audit.Run(context.Background(), client, dtConfig, opts, false)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if report.Checked != 5 || report.Valid != 1 || report.Invalid != 4 || report.Rewritten != 0 {
+		t.Errorf("report counts = %+v", report)
+	}
+	if got := actions(report); !equalStrings(got, []string{"2:planned", "3:skipped", "4:", "5:planned"}) {
+		t.Errorf("dry run actions = %v", got)
+	}
+	if items[2].value != "marketing" {
+		t.Fatalf("dry run rewrote work item 2 to %s", items[2].value)
+	}
+
+	opts.Apply = true
+	report, err = audit.Run(context.Background(), client, dtConfig, opts, true)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got := actions(report); !equalStrings(got, []string{"1:", "2:rewritten", "3:skipped", "4:", "5:failed"}) {
+		t.Errorf("apply actions = %v", got)
+	}
+	if report.Rewritten != 1 || items[2].value != "CC001" || items[5].value != "Marketing" {
+		t.Errorf("rewritten %d, work item 2 = %s, work item 5 = %s", report.Rewritten, items[2].value, items[5].value)
+	}
+	if reason := report.Rows[2].Error; reason != "no cc item with value 'CC998'" {
+		t.Errorf("skipped error = %q", reason)
+	}
+}
+
+func TestDependentSearch(t *testing.T) {
+	handler := setupTestServer(t)
+
+	// Assign WBS elements to cost centers
+	db, err := sql.Open("sqlite3", config.AppConfig.DataTypes[1].Connection.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, statement := range []string{
+		"ALTER TABLE wbs_elements ADD COLUMN cost_center TEXT",
+		"UPDATE wbs_elements SET cost_center = CASE WHEN code IN ('WBS003', 'WBS004') THEN 'CC002' ELSE 'CC001' END",
+	} {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+
+	wbs := &config.AppConfig.DataTypes[1]
+	wbs.Query = "SELECT code AS value, code || ' - ' || name AS label FROM wbs_elements WHERE ({costCenter} = '' OR cost_center = {costCenter}) AND (? = '' OR UPPER(code) LIKE ? OR UPPER(name) LIKE ?) ORDER BY code"
+	wbs.Params = []config.ParamConfig{{Name: "costCenter", DependsOn: "cc", Cascade: config.CascadeRefresh}}
+	if err := providers.ValidateAll(config.GetEnabledDataTypes()); err != nil {
+		t.Fatalf("ValidateAll() error = %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"/api/search/wbs?costCenter=CC001", []string{"WBS001", "WBS002", "WBS005"}},
+		{"/api/search/wbs?costCenter=CC002", []string{"WBS003", "WBS004"}},
+		{"/api/search/wbs?q=bonsai&costCenter=CC001", []string{"WBS001", "WBS005"}},
+		{"/api/search/wbs", []string{"WBS001", "WBS002", "WBS003", "WBS004", "WBS005"}},
+		{"/api/search/wbs?costCenter=CC001'%20OR%20'1'='1", nil},
+	}
+	for _, tt := range tests {
+		if _, response := search(t, handler, tt.path); !equalStrings(values(response.Data), tt.want) {
+			t.Errorf("%s = %v, want %v", tt.path, values(response.Data), tt.want)
+		}
+	}
+
+	// Structured data types filter on the parameter's column
+	wbs.Query = ""
+	wbs.Table = "wbs_elements"
+	wbs.ValueColumn = "code"
+	wbs.LabelColumn = "name"
+	wbs.Params = []config.ParamConfig{{Name: "costCenter", Column: "cost_center", DependsOn: "cc", Required: true}}
+	cache.Instance.Clear()
+	if code, _ := search(t, handler, "/api/search/wbs?q=project"); code != http.StatusBadRequest {
+		t.Errorf("missing required parameter: status %d, want 400", code)
+	}
+	if _, response := search(t, handler, "/api/search/wbs?q=project&costCenter=CC002"); len(response.Data) != 0 {
+		t.Errorf("CC002 projects = %v, want none", values(response.Data))
+	}
+	if _, response := search(t, handler, "/api/search/wbs?q=project&costCenter=CC001"); !equalStrings(values(response.Data), []string{"WBS001", "WBS002"}) {
+		t.Errorf("CC001 projects = %v, want WBS001 and WBS002", values(response.Data))
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/config", nil))
+	var response models.ConfigResponse
+	json.NewDecoder(rec.Body).Decode(&response)
+	want := []models.ParamInfo{{Name: "costCenter", DependsOn: "cc", Required: true, Cascade: config.CascadeClear}}
+	if params := response.DataTypes[1].Params; !reflect.DeepEqual(params, want) {
+		t.Errorf("config params = %+v, want %+v", params, want)
+	}
+
+	wbs.Params[0].Name = "q"
+	if err := providers.ValidateAll(config.GetEnabledDataTypes()); err == nil || !strings.Contains(err.Error(), "param name \"q\"") {
+		t.Errorf("ValidateAll() error = %v, want the reserved name", err)
+	}
+}
+
+func TestItemAttributes(t *testing.T) {
+	handler := setupTestServer(t)
+
+	db, err := sql.Open("sqlite3", config.AppConfig.DataTypes[0].Connection.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, statement := range []string{
+		"ALTER TABLE cost_centers ADD COLUMN owner TEXT",
+		"UPDATE cost_centers SET owner = 'Alice' WHERE code = 'CC001'",
+	} {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+
+	// Extra columns of hand-written queries no longer break scanning and
+	// are returned when configured as attributes
+	cc := &config.AppConfig.DataTypes[0]
+	cc.Query = "SELECT code AS value, name AS label, owner, code AS ignored FROM cost_centers WHERE (? = '' OR UPPER(code) LIKE ? OR UPPER(name) LIKE ?) ORDER BY code"
+	if _, response := search(t, handler, "/api/search/cc?q=CC00"); len(response.Data) != 5 || response.Data[0].Attributes != nil {
+		t.Errorf("search without attributes = %+v, want 5 items without attributes", response.Data)
+	}
+
+	cc.Attributes = []config.AttributeConfig{{Name: "owner", Label: "Owner"}}
+	cache.Instance.Clear()
+	_, response := search(t, handler, "/api/search/cc?q=CC00")
+	if len(response.Data) != 5 || !reflect.DeepEqual(response.Data[0].Attributes, map[string]string{"owner": "Alice"}) || response.Data[1].Attributes != nil {
+		t.Errorf("search = %+v, want CC001 owned by Alice and CC002 without attributes", response.Data)
+	}
+
+	// Structured data types select the attribute columns themselves
+	wbs := &config.AppConfig.DataTypes[1]
+	wbs.Query = ""
+	wbs.Table = "wbs_elements"
+	wbs.ValueColumn = "code"
+	wbs.LabelColumn = "code"
+	wbs.Attributes = []config.AttributeConfig{{Name: "title", Column: "name", Display: config.DisplayBadge}}
+	if err := providers.ValidateAll(config.GetEnabledDataTypes()); err != nil {
+		t.Fatalf("ValidateAll() error = %v", err)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/items/wbs/WBS003", nil))
+	var item models.DropdownItem
+	json.NewDecoder(rec.Body).Decode(&item)
+	if want := mockWBSData[2].Label; item.Attributes["title"] != want {
+		t.Errorf("lookup = %d %+v, want title %q", rec.Code, item, want)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/config", nil))
+	var configResponse models.ConfigResponse
+	json.NewDecoder(rec.Body).Decode(&configResponse)
+	want := [][]models.AttributeInfo{
+		{{Name: "owner", Label: "Owner", Display: config.DisplayText}},
+		{{Name: "title", Label: "title", Display: config.DisplayBadge}},
+	}
+	for i, dt := range configResponse.DataTypes {
+		if !reflect.DeepEqual(dt.Attributes, want[i]) {
+			t.Errorf("%s attributes = %+v, want %+v", dt.ID, dt.Attributes, want[i])
+		}
+	}
+
+	wbs.Attributes[0].Name = "label"
+	if err := providers.ValidateAll(config.GetEnabledDataTypes()); err == nil || !strings.Contains(err.Error(), "attribute name \"label\"") {
+		t.Errorf("ValidateAll() error = %v, want the reserved name", err)
+	}
+}
+
+func TestQueryValidation(t *testing.T) {
+	setupTestServer(t)
+	cc := &config.AppConfig.DataTypes[0]
+
+	cc.Query = "SELECT code AS value, name AS label FROM cost_centers WHERE (? = '' OR UPPER(code) LIKE ?) ORDER BY code"
+	var invalid *providers.ValidationError
+	if err := providers.ValidateAll(config.GetEnabledDataTypes()); !errors.As(err, &invalid) {
+		t.Fatalf("ValidateAll() error = %v, want a *ValidationError", err)
+	}
+	if ids := invalid.DataTypes(); !equalStrings(ids, []string{"cc"}) || !strings.Contains(invalid.Failures["cc"].Error(), "query has 2 placeholders, want 3") {
+		t.Errorf("failures = %v, want the placeholder count of cc", invalid)
+	}
+
+	cc.Query = "SELECT name AS label, code AS value FROM cost_centers WHERE (? = '' OR UPPER(code) LIKE ? OR UPPER(name) LIKE ?)"
+	config.AppConfig.DataTypes[1].Query = "SELECT code AS value, name AS label FROM missing_table WHERE ? = '' OR code LIKE ? OR name LIKE ?"
+	if err := providers.DescribeAll(context.Background(), config.GetEnabledDataTypes()); !errors.As(err, &invalid) {
+		t.Fatalf("DescribeAll() error = %v, want a *ValidationError", err)
+	}
+	if err := invalid.Failures["cc"]; err == nil || !strings.Contains(err.Error(), "columns (label, value)") {
+		t.Errorf("cc error = %v, want the unexpected columns", err)
+	}
+	if err := invalid.Failures["wbs"]; err == nil || !strings.Contains(err.Error(), "no such table") {
+		t.Errorf("wbs error = %v, want the missing table", err)
+	}
+
+	// Columns after the value, label and attributes are ignored
+	cc.Query = "SELECT code AS value, name AS label, code FROM cost_centers WHERE {search} ORDER BY code LIMIT ?"
+	config.AppConfig.DataTypes = config.AppConfig.DataTypes[:1]
+	if err := providers.DescribeAll(context.Background(), config.GetEnabledDataTypes()); err != nil {
+		t.Errorf("DescribeAll() error = %v for a valid query", err)
+	}
+
+	// A snapshot query must return every declared attribute
+	cc = &config.AppConfig.DataTypes[0]
+	cc.Attributes = []config.AttributeConfig{{Name: "owner"}}
+	cc.Query = "SELECT code AS value, name AS label, name AS owner FROM cost_centers WHERE {search} ORDER BY code LIMIT ?"
+	cc.Snapshot = &config.SnapshotConfig{Enabled: true, Query: "SELECT code AS value, name AS label, code, name FROM cost_centers"}
+	if err := providers.DescribeAll(context.Background(), config.GetEnabledDataTypes()); !errors.As(err, &invalid) ||
+		!strings.Contains(invalid.Failures["cc"].Error(), "dataset query returns columns (value, label, code, name)") {
+		t.Errorf("DescribeAll() error = %v, want the snapshot query missing owner", err)
+	}
+	cc.Snapshot.Query = "SELECT code AS value, name AS label, name AS owner, code, name FROM cost_centers"
+	if err := providers.DescribeAll(context.Background(), config.GetEnabledDataTypes()); err != nil {
+		t.Errorf("DescribeAll() error = %v with owner in the snapshot query", err)
+	}
+}
+
+func TestFuzzySearchRanksTypos(t *testing.T) {
+	handler := setupTestServer(t)
+
+	// Without fuzzy mode the misspelling matches nothing
+	if _, response := search(t, handler, "/api/search/cc?q=Enginering"); len(response.Data) != 0 {
+		t.Fatalf("LIKE search matched %v", values(response.Data))
+	}
+
+	// The search query only returns the first results, so hand-written
+	// queries need a candidate query
+	cc := &config.AppConfig.DataTypes[0]
+	cc.Fuzzy = &config.FuzzyConfig{Enabled: true}
+	if err := providers.ValidateAll(config.GetEnabledDataTypes()); err == nil || !strings.Contains(err.Error(), "candidateQuery") {
+		t.Errorf("ValidateAll() error = %v, want candidateQuery required", err)
+	}
+	cc.Fuzzy.CandidateQuery = "SELECT code AS value, code || ' - ' || name AS label, code, name FROM cost_centers"
+	if err := providers.ValidateAll(config.GetEnabledDataTypes()); err != nil {
+		t.Fatalf("ValidateAll() error = %v", err)
+	}
+	cache.Instance.Clear()
+
+	_, response := search(t, handler, "/api/search/cc?q=Enginering")
+	if !equalStrings(values(response.Data), []string{"CC002"}) {
+		t.Fatalf("fuzzy values = %v, want [CC002]", values(response.Data))
+	}
+	if score := response.Data[0].Score; score <= 0 || score >= 1 {
+		t.Errorf("score = %v, want between 0 and 1", score)
+	}
+
+	// Rows matching more tokens rank first
+	config.AppConfig.DataTypes[0].Fuzzy.MinScore = 0.3
+	_, response = search(t, handler, "/api/search/cc?q=showbot%20sales")
+	if got := values(response.Data); !equalStrings(got, []string{"CC003", "CC001"}) {
+		t.Errorf("ranked values = %v, want [CC003 CC001]", got)
+	}
+}
+
+func TestFuzzyFileRanksPastMaxResults(t *testing.T) {
+	handler := setupTestServer(t)
+	config.AppConfig.SearchSettings.MaxResults = 2
+
+	path := filepath.Join(t.TempDir(), "offices.csv")
+	csv := "code,city\nAMS,Amsterdam\nBER,Berlin\nCPH,Copenhagen\nDUB,Dublin\nSEA,Seattle\n"
+	if err := os.WriteFile(path, []byte(csv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config.AppConfig.DataTypes = append(config.AppConfig.DataTypes, config.DataTypeConfig{
+		ID:       "offices",
+		Provider: "file",
+		File:     &config.FileSourceConfig{Path: path, ValueColumn: "code", LabelColumn: "city"},
+		Fuzzy:    &config.FuzzyConfig{Enabled: true},
+		Enabled:  true,
+	})
+	if err := providers.ValidateAll(config.GetEnabledDataTypes()); err != nil {
+		t.Fatalf("ValidateAll() error = %v", err)
+	}
+
+	// The best match is the last row, past maxResults
+	_, response := search(t, handler, "/api/search/offices?q=Seatle")
+	if got := values(response.Data); len(got) == 0 || got[0] != "SEA" {
+		t.Errorf("fuzzy values = %v, want SEA first", got)
+	}
+}
+
+// admin performs an admin API request and returns the recorded response
+func admin(handler http.Handler, method, path, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set("X-Admin-Key", key)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCacheAdmin(t *testing.T) {
+	handler := setupTestServer(t)
+
+	if rec := admin(handler, http.MethodGet, "/api/admin/cache", "", ""); rec.Code != http.StatusForbidden {
+		t.Errorf("status without ADMIN_API_KEY = %d, want 403", rec.Code)
+	}
+	t.Setenv("ADMIN_API_KEY", "secret")
+	if rec := admin(handler, http.MethodGet, "/api/admin/cache", "wrong", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("status with wrong key = %d, want 401", rec.Code)
+	}
+	if rec := admin(handler, http.MethodGet, "/api/admin/jobs", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("job status without key = %d, want 401", rec.Code)
+	}
+	if rec := admin(handler, http.MethodDelete, "/api/admin/cache", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("clear without key = %d, want 401", rec.Code)
+	}
+	if rec := admin(handler, http.MethodDelete, "/api/admin/cache/cc", "wrong", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("invalidate with wrong key = %d, want 401", rec.Code)
+	}
+	// The cache can only be cleared through the admin routes
+	for _, path := range []string{"/api/cache", "/api/cache/cc"} {
+		if rec := admin(handler, http.MethodDelete, path, "", ""); rec.Code != http.StatusNotFound && rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("DELETE %s = %d, want no such route", path, rec.Code)
+		}
+	}
+
+	// Warm the cost centers for the empty term and two prefixes
+	rec := admin(handler, http.MethodPost, "/api/admin/cache/warm", "secret", `{"types": ["cc"], "prefixes": ["", "sales", "eng"]}`)
+	var warmed []struct {
+		Rows  int    `json:"rows"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&warmed); err != nil || len(warmed) != 3 {
+		t.Fatalf("warm = %d %v, %v", rec.Code, warmed, err)
+	}
+	if warmed[0].Rows != len(mockCCData) || warmed[1].Rows != 1 || warmed[1].Error != "" {
+		t.Errorf("warm results = %+v", warmed)
+	}
+	if _, response := search(t, handler, "/api/search/cc?q=Sales"); !response.Metadata.Cached {
+		t.Error("warmed search was not served from the cache")
+	}
+
+	var stats map[string]struct {
+		Entries int   `json:"entries"`
+		Hits    int64 `json:"hits"`
+	}
+	rec = admin(handler, http.MethodGet, "/api/admin/cache", "secret", "")
+	if err := json.NewDecoder(rec.Body).Decode(&stats); err != nil {
+		t.Fatalf("decode stats: %v", err)
+	}
+	if cc := stats["cc"]; cc.Entries != 3 || cc.Hits < 1 {
+		t.Errorf("cc stats = %+v, want 3 entries and a hit", cc)
+	}
+
+	// Invalidate by key pattern, then by data type
+	rec = admin(handler, http.MethodDelete, "/api/admin/cache?pattern=search:cc:e*", "secret", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"deleted":1`) {
+		t.Errorf("pattern delete = %d %s", rec.Code, rec.Body.String())
+	}
+	search(t, handler, "/api/search/wbs?q=bonsai")
+	if rec := admin(handler, http.MethodDelete, "/api/admin/cache/cc", "secret", ""); rec.Code != http.StatusNoContent {
+		t.Errorf("type delete status = %d", rec.Code)
+	}
+	if keys := cache.Instance.Keys("search:"); !equalStrings(keys, []string{"search:wbs:bonsai"}) {
+		t.Errorf("keys after invalidating cc = %v", keys)
+	}
+
+	if rec := admin(handler, http.MethodDelete, "/api/admin/cache", "secret", ""); rec.Code != http.StatusNoContent {
+		t.Errorf("clear status = %d", rec.Code)
+	}
+	if keys := cache.Instance.Keys(""); len(keys) != 0 {
+		t.Errorf("keys after clear = %v", keys)
+	}
+}
+
+func TestSearchUnknownType(t *testing.T) {
+	handler := setupTestServer(t)
+
+	if code, _ := search(t, handler, "/api/search/unknown?q=x"); code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", code)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
 }